@@ -0,0 +1,230 @@
+// Go/analyzer.go
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// errNoClaims - в ответе не нашлось ни утверждений, ни Go-кода
+var errNoClaims = errors.New("утверждений не найдено")
+
+// Уровни сообщений о ходе проверки
+const (
+	noteInfo = "info"
+	noteOK   = "ok"
+	noteWarn = "warn"
+)
+
+// Analyzer - пайплайн проверки без вывода в терминал. Его используют REPL,
+// неинтерактивные команды и HTTP-сервер; безопасен для параллельных вызовов.
+type Analyzer struct {
+	python  *PythonClient
+	jinaKey string
+
+	// History - куда сохранять завершённые проверки; nil - не сохранять
+	History *HistoryStore
+}
+
+// NewAnalyzer создает пайплайн поверх Python API и Jina
+func NewAnalyzer(python *PythonClient, jinaKey string) *Analyzer {
+	return &Analyzer{python: python, jinaKey: jinaKey}
+}
+
+// Analyze извлекает утверждения, проверяет их и собирает AnalysisResult.
+// Ход проверки передаётся в onEvent (может быть nil): каждое утверждение
+// приходит уже с вердиктом, сразу как проверено. Предупреждения (недоступна
+// база мифов, политика и т.п.) не прерывают проверку и сохраняются в Warnings.
+func (a *Analyzer) Analyze(query, response string, opts checkOptions, onEvent func(AnalysisEvent)) (*AnalysisResult, error) {
+	run := newAnalysisRun(onEvent)
+	emit, notify := run.emit, run.notify
+	fail := func(err error) (*AnalysisResult, error) {
+		emit(AnalysisEvent{Type: EventError, Message: err.Error()})
+		return nil, err
+	}
+
+	codeResults := NewGoCodeChecker().CheckResponse(response)
+	// Утверждения извлекаются из текста без разметки; код уже проверен выше.
+	// Место в ответе ищется и ответ сохраняется в исходном виде, с кодом.
+	text := NormalizeInput(response)
+
+	notify(noteInfo, "📝 Извлечение утверждений...")
+	result, err := a.python.ExtractAndSave(query, text)
+	if err != nil {
+		return fail(fmt.Errorf("ошибка извлечения: %w", err))
+	}
+	notify(noteOK, "✅ Сохранено в: %s", result.Filename)
+
+	if result.ClaimsCount == 0 && len(codeResults) == 0 {
+		return fail(errNoClaims)
+	}
+
+	// Файл Python API именуется с точностью до секунды, и параллельные проверки
+	// перезаписывают его; утверждения берутся только из ответа на запрос
+	claimsData := ClaimsData{Query: query, Response: response, Claims: result.Claims, Spans: rebaseSpans(result.Spans, text, response)}
+	emit(AnalysisEvent{Type: EventExtracted, Claims: claimsData.Claims, Total: len(claimsData.Claims) + len(codeResults)})
+
+	stages := loadClaimStages(notify)
+
+	// Все этапы после проверки работают с каждым утверждением отдельно,
+	// поэтому результат доводится до окончательного и отправляется сразу
+	total := len(claimsData.Claims) + len(codeResults)
+	finalize := func(results []FactCheckResult, i int) {
+		stages.finalize(results[i:i+1], response, opts.ReferenceDate, claimsData.Spans)
+		r := results[i]
+		emit(AnalysisEvent{Type: EventClaim, Index: i + 1, Total: total, Result: &r})
+	}
+
+	results, pending := stages.myths.Partition(claimsData.Claims)
+	if known := len(claimsData.Claims) - len(pending); known > 0 {
+		notify(noteInfo, "📚 Известных заблуждений: %d", known)
+	}
+	isPending := make(map[int]bool, len(pending))
+	for _, idx := range pending {
+		isPending[idx] = true
+	}
+	for i := range results {
+		if !isPending[i] {
+			finalize(results, i)
+		}
+	}
+
+	if len(pending) > 0 {
+		pendingClaims := make([]string, len(pending))
+		for i, idx := range pending {
+			pendingClaims[i] = claimsData.Claims[idx]
+		}
+
+		notify(noteInfo, "🔎 Проверка через Jina AI Grounding API...")
+		api := NewJinaClient(a.jinaKey)
+		api.ReferenceDate = opts.ReferenceDate
+		api.OnResult = func(i int, checked FactCheckResult) {
+			results[pending[i]] = checked
+			finalize(results, pending[i])
+		}
+		if _, err := api.CheckClaims(pendingClaims); err != nil {
+			return fail(fmt.Errorf("ошибка проверки: %w", err))
+		}
+	}
+
+	if len(codeResults) > 0 {
+		notify(noteInfo, "🧩 В Go-коде найдено несуществующих идентификаторов: %d", len(codeResults))
+		results = append(results, codeResults...)
+		for i := len(results) - len(codeResults); i < len(results); i++ {
+			finalize(results, i)
+		}
+	}
+
+	analysis := &AnalysisResult{
+		Query:            claimsData.Query,
+		Response:         claimsData.Response,
+		Claims:           claimsData.Claims,
+		FactCheckResults: results,
+		Summary:          BuildSummary(results),
+		Risk:             ScoreRisk(results),
+		Relevance:        ScoreRelevance(claimsData.Query, text, results),
+	}
+
+	return a.complete(run, analysis, opts), nil
+}
+
+// analysisRun - события, предупреждения и время одной проверки
+type analysisRun struct {
+	onEvent  func(AnalysisEvent)
+	warnings []string
+	started  time.Time
+}
+
+func newAnalysisRun(onEvent func(AnalysisEvent)) *analysisRun {
+	return &analysisRun{onEvent: onEvent, started: time.Now()}
+}
+
+func (r *analysisRun) emit(e AnalysisEvent) {
+	if r.onEvent != nil {
+		r.onEvent(e)
+	}
+}
+
+// notify сообщает о ходе проверки; предупреждения попадают в Warnings результата
+func (r *analysisRun) notify(level, format string, args ...any) {
+	message := fmt.Sprintf(format, args...)
+	if level == noteWarn {
+		r.warnings = append(r.warnings, message)
+	}
+	r.emit(AnalysisEvent{Type: EventNote, Level: level, Message: message})
+}
+
+// complete применяет политику, сохраняет проверку в историю и отправляет summary
+func (a *Analyzer) complete(run *analysisRun, analysis *AnalysisResult, opts checkOptions) *AnalysisResult {
+	policy := opts.Policy
+	if policy == nil && opts.PolicyFile != "" {
+		var err error
+		if policy, err = LoadPolicy(opts.PolicyFile); err != nil {
+			run.notify(noteWarn, "Политика не применена: %v", err)
+		}
+	}
+	if policy != nil {
+		report := policy.Evaluate(analysis)
+		analysis.Policy = &report
+	}
+
+	analysis.Model = opts.Model
+	analysis.Topic = ClassifyTopic(analysis.Query, analysis.Claims)
+	analysis.CheckedAt = run.started.UTC()
+	analysis.DurationMs = time.Since(run.started).Milliseconds()
+	analysis.Warnings = run.warnings
+	if a.History != nil {
+		if _, err := a.History.Save(analysis); err != nil {
+			run.notify(noteWarn, "История не сохранена: %v", err)
+			analysis.Warnings = run.warnings
+		}
+	}
+
+	run.emit(AnalysisEvent{Type: EventSummary, Analysis: analysis})
+	return analysis
+}
+
+// claimStages - общие для всех режимов этапы доводки результата проверки
+type claimStages struct {
+	myths       *MisconceptionDB
+	sources     *SourcePolicy
+	thresholds  VerdictThresholds
+	calibration *Calibration
+}
+
+// loadClaimStages загружает базу мифов, источники, пороги и калибровку; недоступные
+// настройки не мешают проверке и отмечаются предупреждением
+func loadClaimStages(notify func(level, format string, args ...any)) *claimStages {
+	myths, err := LoadMisconceptionDB(misconceptionsFile)
+	if err != nil {
+		notify(noteWarn, "База заблуждений недоступна: %v", err)
+	}
+	sources, err := LoadSourcePolicy(sourcesFile)
+	if err != nil {
+		notify(noteWarn, "Настройки источников недоступны: %v", err)
+	}
+	thresholds, err := LoadVerdictThresholds(verdictsFile)
+	if err != nil {
+		notify(noteWarn, "Пороги вердиктов по умолчанию: %v", err)
+	}
+	calibration, err := LoadCalibration(calibrationFile)
+	if err != nil {
+		notify(noteWarn, "Калибровка не применена: %v", err)
+	}
+	return &claimStages{myths: myths, sources: sources, thresholds: thresholds, calibration: calibration}
+}
+
+// finalize доводит результаты до окончательных: доверие к источникам, вердикт,
+// калибровка уверенности, контекст времени, расхождения с цитатой, тип
+// утверждения и место в ответе
+func (s *claimStages) finalize(results []FactCheckResult, response, referenceDate string, spans []ClaimSpan) {
+	s.sources.Apply(results)
+	s.thresholds.ClassifyAll(results)
+	s.calibration.Apply(results)
+	ApplyTemporalContext(results, response, referenceDate)
+	AnalyzeQuoteMismatches(results)
+	AssignClaimTypes(results)
+	AlignResults(response, results, spans)
+}
@@ -0,0 +1,72 @@
+// Go/annotate.go
+
+package main
+
+// annotatedSegment - кусок ответа, принадлежащий одному утверждению (или никому).
+// Footnotes - номера утверждений, чьи фрагменты заканчиваются на этом куске.
+type annotatedSegment struct {
+	Text      string
+	Claim     int // индекс в FactCheckResults, -1 - обычный текст
+	Verdict   Verdict
+	Footnotes []int
+}
+
+// Приоритет подсветки при пересечении фрагментов: опровержение важнее всего
+var verdictPriority = map[Verdict]int{
+	VerdictRefuted:      5,
+	VerdictDisputed:     4,
+	VerdictUnverifiable: 3,
+	VerdictError:        2,
+	VerdictSupported:    1,
+}
+
+// annotateResponse размечает ответ по фрагментам утверждений
+func annotateResponse(response string, results []FactCheckResult) []annotatedSegment {
+	runes := []rune(response)
+	owner := make([]int, len(runes))
+	for i := range owner {
+		owner[i] = -1
+	}
+	footnotesAt := make(map[int][]int)
+
+	for i, r := range results {
+		if r.Span == nil || r.Span.Start == nil || r.Span.End == nil {
+			continue
+		}
+		start, end := *r.Span.Start, *r.Span.End
+		if start < 0 || end > len(runes) || start >= end {
+			continue
+		}
+
+		for pos := start; pos < end; pos++ {
+			if owner[pos] < 0 || verdictPriority[r.Verdict] > verdictPriority[results[owner[pos]].Verdict] {
+				owner[pos] = i
+			}
+		}
+		footnotesAt[end-1] = append(footnotesAt[end-1], i+1)
+	}
+
+	var segments []annotatedSegment
+	segStart := 0
+	for pos := 0; pos <= len(runes); pos++ {
+		boundary := pos == len(runes) ||
+			(pos > segStart && owner[pos] != owner[pos-1]) ||
+			(pos > segStart && footnotesAt[pos-1] != nil)
+		if !boundary || pos == segStart {
+			continue
+		}
+
+		seg := annotatedSegment{
+			Text:      string(runes[segStart:pos]),
+			Claim:     owner[segStart],
+			Footnotes: footnotesAt[pos-1],
+		}
+		if seg.Claim >= 0 {
+			seg.Verdict = results[seg.Claim].Verdict
+		}
+		segments = append(segments, seg)
+		segStart = pos
+	}
+
+	return segments
+}
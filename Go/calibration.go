@@ -0,0 +1,541 @@
+// Go/calibration.go
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/muesli/termenv"
+)
+
+const (
+	calibrationFile = "data/calibration.json"
+
+	// Методы калибровки: изотоническая регрессия точнее, но ей нужно больше меток
+	CalibrationIsotonic = "isotonic"
+	CalibrationPlatt    = "platt"
+	CalibrationAuto     = "auto"
+
+	// minCalibrationSamples - меньше меток у проверщика - калибровка не строится
+	minCalibrationSamples = 20
+	// isotonicMinSamples - с какого числа меток auto выбирает изотоническую регрессию
+	isotonicMinSamples = 100
+)
+
+// CalibrationPoint - узел изотонической функции: оценка X даёт вероятность Y
+type CalibrationPoint struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// CalibrationModel - калибровка одного проверщика: Platt - 1/(1+e^(A·x+B)),
+// изотоническая - кусочно-линейная по Points. Brier до и после - на тех же метках.
+type CalibrationModel struct {
+	Method      string             `json:"method"`
+	Samples     int                `json:"samples"`
+	A           float64            `json:"a,omitempty"`
+	B           float64            `json:"b,omitempty"`
+	Points      []CalibrationPoint `json:"points,omitempty"`
+	BrierBefore float64            `json:"brier_before"`
+	BrierAfter  float64            `json:"brier_after"`
+}
+
+// Calibration - модели по имени проверщика (jina, misconceptions, ...)
+type Calibration struct {
+	FittedAt time.Time                    `json:"fitted_at"`
+	Models   map[string]*CalibrationModel `json:"models"`
+}
+
+// CalibrationSample - метка рецензента: оценка проверщика и верно ли утверждение
+type CalibrationSample struct {
+	Verifier string
+	Score    float64
+	Truth    bool
+}
+
+// LoadCalibration читает калибровку; без файла (или с пустым путём) - nil, оценки как есть
+func LoadCalibration(path string) (*Calibration, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("не удалось прочитать %s: %w", path, err)
+	}
+
+	var c Calibration
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("ошибка парсинга %s: %w", path, err)
+	}
+	for verifier, m := range c.Models {
+		if m.Method != CalibrationIsotonic && m.Method != CalibrationPlatt {
+			return nil, fmt.Errorf("%s: неизвестный метод %q у %s", path, m.Method, verifier)
+		}
+	}
+	return &c, nil
+}
+
+// Save записывает калибровку в JSON
+func (c *Calibration) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("ошибка сериализации: %w", err)
+	}
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("не удалось создать папку: %w", err)
+		}
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// Predict переводит оценку проверщика в вероятность того, что утверждение верно
+func (m *CalibrationModel) Predict(x float64) float64 {
+	if m.Method == CalibrationPlatt {
+		return 1 / (1 + math.Exp(m.A*x+m.B))
+	}
+
+	points := m.Points
+	switch {
+	case len(points) == 0:
+		return x
+	case x <= points[0].X:
+		return points[0].Y
+	case x >= points[len(points)-1].X:
+		return points[len(points)-1].Y
+	}
+	i := sort.Search(len(points), func(i int) bool { return points[i].X >= x })
+	lo, hi := points[i-1], points[i]
+	return lo.Y + (hi.Y-lo.Y)*(x-lo.X)/(hi.X-lo.X)
+}
+
+// Apply заменяет Confidence откалиброванной; исходная остаётся в RawConfidence.
+// Вердикт не меняется: он по-прежнему выносится по порогам factuality.
+func (c *Calibration) Apply(results []FactCheckResult) {
+	if c == nil {
+		return
+	}
+	for i := range results {
+		r := &results[i]
+		m := c.Models[r.Verifier]
+		if m == nil || !r.Found || r.Error != "" || r.Calibration != "" {
+			continue
+		}
+		p := m.Predict(truthProbability(*r))
+		r.RawConfidence = r.Confidence
+		r.Calibration = m.Method
+		r.Confidence = fromTruthProbability(*r, p)
+	}
+}
+
+// displayFactuality - вероятность того, что утверждение верно, для отчётов:
+// откалиброванная, если есть калибровка, иначе factuality проверщика
+func displayFactuality(r FactCheckResult) float64 {
+	if r.Calibration != "" {
+		return truthProbability(r)
+	}
+	return r.Factuality
+}
+
+// truthProbability - уверенность проверщика в том, что утверждение верно.
+// У Jina Confidence - это factuality; локальные проверщики (база мифов,
+// Go-код) пишут в Confidence уверенность в собственном вердикте.
+func truthProbability(r FactCheckResult) float64 {
+	if r.Verifier != "jina" && r.Verdict == VerdictRefuted {
+		return 1 - r.Confidence
+	}
+	return r.Confidence
+}
+
+// fromTruthProbability - обратное к truthProbability: Confidence по вероятности верности
+func fromTruthProbability(r FactCheckResult, p float64) float64 {
+	if r.Verifier != "jina" && r.Verdict == VerdictRefuted {
+		return 1 - p
+	}
+	return p
+}
+
+// FitCalibration строит модели по меткам; проверщики, у которых меток меньше
+// minSamples, пропускаются и возвращаются в skipped с числом меток
+func FitCalibration(samples []CalibrationSample, method string, minSamples int) (*Calibration, map[string]int) {
+	byVerifier := make(map[string][]CalibrationSample)
+	for _, s := range samples {
+		byVerifier[s.Verifier] = append(byVerifier[s.Verifier], s)
+	}
+
+	c := &Calibration{FittedAt: time.Now().UTC(), Models: make(map[string]*CalibrationModel)}
+	skipped := make(map[string]int)
+	for verifier, list := range byVerifier {
+		if len(list) < minSamples {
+			skipped[verifier] = len(list)
+			continue
+		}
+		m := &CalibrationModel{Method: method, Samples: len(list)}
+		if method == CalibrationAuto {
+			m.Method = CalibrationPlatt
+			if len(list) >= isotonicMinSamples {
+				m.Method = CalibrationIsotonic
+			}
+		}
+		if m.Method == CalibrationIsotonic {
+			m.Points = fitIsotonic(list)
+		} else {
+			m.A, m.B = fitPlatt(list)
+		}
+		for _, s := range list {
+			m.BrierBefore += brier(s.Score, s.Truth)
+			m.BrierAfter += brier(m.Predict(s.Score), s.Truth)
+		}
+		m.BrierBefore /= float64(len(list))
+		m.BrierAfter /= float64(len(list))
+		c.Models[verifier] = m
+	}
+	return c, skipped
+}
+
+func brier(p float64, truth bool) float64 {
+	if truth {
+		return (1 - p) * (1 - p)
+	}
+	return p * p
+}
+
+// fitIsotonic - изотоническая регрессия (pool adjacent violators): соседние
+// группы сливаются, пока доля верных не станет неубывающей по оценке.
+// Узлы - средняя оценка и доля верных каждой группы. Образцы с одинаковой
+// оценкой с самого начала в одной группе: иначе итог зависел бы от их порядка.
+func fitIsotonic(samples []CalibrationSample) []CalibrationPoint {
+	sorted := make([]CalibrationSample, len(samples))
+	copy(sorted, samples)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Score < sorted[j].Score })
+
+	type block struct{ sumX, sumY, n float64 }
+	var blocks []block
+	for i := 0; i < len(sorted); {
+		b := block{}
+		j := i
+		for ; j < len(sorted) && sorted[j].Score == sorted[i].Score; j++ {
+			b.sumX += sorted[j].Score
+			b.n++
+			if sorted[j].Truth {
+				b.sumY++
+			}
+		}
+		i = j
+		blocks = append(blocks, b)
+		for len(blocks) > 1 {
+			last, prev := blocks[len(blocks)-1], blocks[len(blocks)-2]
+			if prev.sumY/prev.n < last.sumY/last.n {
+				break
+			}
+			blocks = blocks[:len(blocks)-1]
+			blocks[len(blocks)-1] = block{sumX: prev.sumX + last.sumX, sumY: prev.sumY + last.sumY, n: prev.n + last.n}
+		}
+	}
+
+	points := make([]CalibrationPoint, len(blocks))
+	for i, b := range blocks {
+		points[i] = CalibrationPoint{X: b.sumX / b.n, Y: b.sumY / b.n}
+	}
+	return points
+}
+
+// fitPlatt подбирает A и B методом Ньютона по логистической функции потерь.
+// Цели сглажены, как у Платта: (N₊+1)/(N₊+2) и 1/(N₋+2), чтобы на малом
+// числе меток вероятности не уходили в 0 и 1.
+func fitPlatt(samples []CalibrationSample) (a, b float64) {
+	var positives, negatives float64
+	for _, s := range samples {
+		if s.Truth {
+			positives++
+		} else {
+			negatives++
+		}
+	}
+	hi, lo := (positives+1)/(positives+2), 1/(negatives+2)
+	target := func(s CalibrationSample) float64 {
+		if s.Truth {
+			return hi
+		}
+		return lo
+	}
+	loss := func(a, b float64) float64 {
+		total := 0.0
+		for _, s := range samples {
+			// log(1+e^z) без переполнения при больших z
+			z, t := a*s.Score+b, target(s)
+			total += t*z + math.Max(-z, 0) + math.Log1p(math.Exp(-math.Abs(z)))
+		}
+		return total
+	}
+
+	a, b = 0, math.Log((negatives+1)/(positives+1))
+	current := loss(a, b)
+	for range 100 {
+		var gA, gB, hAA, hAB, hBB float64
+		for _, s := range samples {
+			p := 1 / (1 + math.Exp(a*s.Score+b))
+			d := target(s) - p
+			w := p * (1 - p)
+			gA += d * s.Score
+			gB += d
+			hAA += w * s.Score * s.Score
+			hAB += w * s.Score
+			hBB += w
+		}
+		if math.Abs(gA) < 1e-6 && math.Abs(gB) < 1e-6 {
+			break
+		}
+		hAA += 1e-12
+		hBB += 1e-12
+		det := hAA*hBB - hAB*hAB
+		dA := (hBB*gA - hAB*gB) / det
+		dB := (hAA*gB - hAB*gA) / det
+
+		// Шаг уменьшается, пока потери не снизятся
+		step := 1.0
+		for ; step > 1e-10; step /= 2 {
+			if next := loss(a-step*dA, b-step*dB); next < current {
+				a, b, current = a-step*dA, b-step*dB, next
+				break
+			}
+		}
+		if step <= 1e-10 {
+			break
+		}
+	}
+	return a, b
+}
+
+// LabeledClaim - вердикт из истории для рецензии
+type LabeledClaim struct {
+	AnalysisID int64
+	Position   int
+	Claim      string
+	Verdict    Verdict
+	Verifier   string
+	Confidence float64
+	Source     string
+}
+
+// LabelClaim сохраняет оценку рецензента: верен ли вердикт утверждения
+// position (с 1) в проверке analysisID; повторная оценка заменяет прежнюю
+func (h *HistoryStore) LabelClaim(analysisID int64, position int, correct bool) error {
+	var exists int
+	err := h.db.QueryRow("SELECT COUNT(*) FROM claims WHERE analysis_id = ? AND position = ?", analysisID, position).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("ошибка чтения истории: %w", err)
+	}
+	if exists == 0 {
+		return fmt.Errorf("#%d, утверждение %d: %w", analysisID, position, errHistoryNotFound)
+	}
+	_, err = h.db.Exec(`INSERT INTO claim_labels (analysis_id, position, correct, labeled_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT (analysis_id, position) DO UPDATE SET correct = excluded.correct, labeled_at = excluded.labeled_at`,
+		analysisID, position, correct, time.Now().UTC().Format(time.RFC3339Nano))
+	if err != nil {
+		return fmt.Errorf("ошибка сохранения оценки: %w", err)
+	}
+	return nil
+}
+
+// UnlabeledClaims - последние подтверждённые и опровергнутые утверждения без
+// оценки рецензента: по спорным и непроверяемым верность не определить
+func (h *HistoryStore) UnlabeledClaims(limit int) ([]LabeledClaim, error) {
+	rows, err := h.db.Query(`SELECT c.analysis_id, c.position, c.claim, c.verdict, c.verifier,
+		COALESCE(c.raw_confidence, c.confidence), c.source
+		FROM claims c LEFT JOIN claim_labels l ON l.analysis_id = c.analysis_id AND l.position = c.position
+		WHERE l.analysis_id IS NULL AND c.verdict IN ('supported', 'refuted') AND c.verifier <> ''
+		ORDER BY c.analysis_id DESC, c.position LIMIT ?`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения истории: %w", err)
+	}
+	defer rows.Close()
+
+	var claims []LabeledClaim
+	for rows.Next() {
+		var c LabeledClaim
+		var verdict string
+		if err := rows.Scan(&c.AnalysisID, &c.Position, &c.Claim, &verdict, &c.Verifier, &c.Confidence, &c.Source); err != nil {
+			return nil, fmt.Errorf("ошибка чтения истории: %w", err)
+		}
+		c.Verdict = Verdict(verdict)
+		claims = append(claims, c)
+	}
+	return claims, rows.Err()
+}
+
+// CalibrationSamples - метки рецензентов с исходной (до калибровки) оценкой.
+// Верный вердикт supported и неверный refuted значат, что утверждение верно.
+func (h *HistoryStore) CalibrationSamples() ([]CalibrationSample, error) {
+	rows, err := h.db.Query(`SELECT c.verifier, c.verdict, COALESCE(c.raw_confidence, c.confidence), l.correct
+		FROM claim_labels l JOIN claims c ON c.analysis_id = l.analysis_id AND c.position = l.position
+		WHERE c.verdict IN ('supported', 'refuted') AND c.verifier <> ''`)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения оценок: %w", err)
+	}
+	defer rows.Close()
+
+	var samples []CalibrationSample
+	for rows.Next() {
+		var r FactCheckResult
+		var verdict string
+		var correct bool
+		if err := rows.Scan(&r.Verifier, &verdict, &r.Confidence, &correct); err != nil {
+			return nil, fmt.Errorf("ошибка чтения оценок: %w", err)
+		}
+		r.Verdict = Verdict(verdict)
+		samples = append(samples, CalibrationSample{
+			Verifier: r.Verifier,
+			Score:    truthProbability(r),
+			Truth:    correct == (r.Verdict == VerdictSupported),
+		})
+	}
+	return samples, rows.Err()
+}
+
+// parseLabel - оценка рецензента: верно / неверно
+func parseLabel(text string) (correct, ok bool) {
+	switch strings.ToLower(strings.TrimSpace(text)) {
+	case "верно", "да", "д", "y", "yes", "correct", "+":
+		return true, true
+	case "неверно", "нет", "н", "n", "no", "incorrect", "-":
+		return false, true
+	}
+	return false, false
+}
+
+// runLabel - /label N верно|неверно: оценка вердикта последней проверки
+func runLabel(last *AnalysisResult, parts []string, p termenv.Profile) {
+	colorErr := p.Color("#FF6B6B")
+	colorOk := p.Color("#3FB950")
+
+	if last == nil || last.HistoryID == 0 {
+		fmt.Println(termenv.String("  ❌ Сначала выполните /check или /show N: оценка сохраняется в историю").Foreground(colorErr))
+		return
+	}
+	n := 0
+	correct, ok := false, false
+	if len(parts) > 2 {
+		n, _ = strconv.Atoi(parts[1])
+		correct, ok = parseLabel(parts[2])
+	}
+	if n < 1 || n > len(last.FactCheckResults) || !ok {
+		fmt.Println(termenv.String(fmt.Sprintf("  ❌ Укажите утверждение от 1 до %d и оценку: /label N верно|неверно", len(last.FactCheckResults))).Foreground(colorErr))
+		return
+	}
+
+	history, err := sharedHistory()
+	if err == nil {
+		err = history.LabelClaim(last.HistoryID, n, correct)
+	}
+	if err != nil {
+		fmt.Println(termenv.String(fmt.Sprintf("  ❌ %v", err)).Foreground(colorErr))
+		return
+	}
+	label := "неверен"
+	if correct {
+		label = "верен"
+	}
+	fmt.Println(termenv.String(fmt.Sprintf("  ✅ Вердикт [%d] отмечен как %s", n, label)).Foreground(colorOk))
+}
+
+// runCalibrationReview показывает вердикты без оценки по одному и спрашивает,
+// верны ли они; пустая строка - пропустить, q - закончить
+func runCalibrationReview(history *HistoryStore, limit int, in *bufio.Scanner, p termenv.Profile) (int, error) {
+	colorHeader := p.Color("#00BFFF")
+	colorDim := p.Color("#8B949E")
+
+	claims, err := history.UnlabeledClaims(limit)
+	if err != nil {
+		return 0, err
+	}
+	if len(claims) == 0 {
+		fmt.Println(termenv.String("  Все вердикты в истории уже оценены").Foreground(colorDim))
+		return 0, nil
+	}
+
+	labeled := 0
+	for i, c := range claims {
+		fmt.Println(termenv.String(fmt.Sprintf("\n  (%d/%d) #%d [%d] %s", i+1, len(claims), c.AnalysisID, c.Position, c.Claim)).Foreground(colorHeader))
+		fmt.Printf("      %s %s, %s: %.0f%%\n", verdictIcon(c.Verdict), verdictLabel(c.Verdict), c.Verifier, truthProbability(FactCheckResult{Verifier: c.Verifier, Verdict: c.Verdict, Confidence: c.Confidence})*100)
+		if c.Source != "" {
+			fmt.Println(termenv.String("      🔗 " + c.Source).Foreground(colorDim))
+		}
+
+		for {
+			fmt.Print(termenv.String("      Вердикт верен? [д/н, Enter - пропустить, q - выйти] ").Foreground(colorDim))
+			if !in.Scan() {
+				return labeled, in.Err()
+			}
+			answer := strings.TrimSpace(in.Text())
+			if answer == "" {
+				break
+			}
+			if answer == "q" || answer == "й" {
+				return labeled, nil
+			}
+			correct, ok := parseLabel(answer)
+			if !ok {
+				continue
+			}
+			if err := history.LabelClaim(c.AnalysisID, c.Position, correct); err != nil {
+				return labeled, err
+			}
+			labeled++
+			break
+		}
+	}
+	return labeled, nil
+}
+
+// printCalibration - модели по проверщикам и качество на метках
+func printCalibration(c *Calibration, skipped map[string]int) {
+	p := termenv.ColorProfile()
+	colorHeader := p.Color("#00BFFF")
+	colorDim := p.Color("#8B949E")
+	colorOk := p.Color("#3FB950")
+
+	if c == nil || len(c.Models) == 0 {
+		fmt.Println(termenv.String("  Калибровки нет: оценки проверщиков показываются как есть").Foreground(colorDim))
+	} else {
+		verifiers := make([]string, 0, len(c.Models))
+		for v := range c.Models {
+			verifiers = append(verifiers, v)
+		}
+		sort.Strings(verifiers)
+
+		fmt.Println(termenv.String(fmt.Sprintf("\n  📐 Калибровка от %s", c.FittedAt.Local().Format("2006-01-02 15:04"))).Foreground(colorHeader).Bold())
+		for _, v := range verifiers {
+			m := c.Models[v]
+			fmt.Printf("     %-16s %-9s меток: %-5d ", v, m.Method, m.Samples)
+			color := colorDim
+			if m.BrierAfter < m.BrierBefore {
+				color = colorOk
+			}
+			fmt.Println(termenv.String(fmt.Sprintf("Brier %.3f → %.3f", m.BrierBefore, m.BrierAfter)).Foreground(color))
+
+			var row strings.Builder
+			for _, x := range []float64{0.1, 0.3, 0.5, 0.7, 0.9} {
+				fmt.Fprintf(&row, "  %.0f%%→%.0f%%", x*100, m.Predict(x)*100)
+			}
+			fmt.Println(termenv.String("                     " + row.String()).Foreground(colorDim))
+		}
+	}
+
+	for v, n := range skipped {
+		fmt.Println(termenv.String(fmt.Sprintf("     %-16s мало меток: %d из %d нужных", v, n, minCalibrationSamples)).Foreground(colorDim))
+	}
+}
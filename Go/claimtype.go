@@ -0,0 +1,94 @@
+// Go/claimtype.go
+
+package main
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Типы утверждений: от них зависит вес в оценке риска и правила политик
+const (
+	ClaimTypeCode    = "code"
+	ClaimTypeDate    = "date"
+	ClaimTypeNumeric = "numeric"
+	ClaimTypeEntity  = "entity"
+	ClaimTypeGeneral = "general"
+)
+
+var (
+	// Даты: год - только с признаком даты ("в 1380 году", "in 1492", "1939–1945",
+	// "1990-х"), а не любое четырёхзначное число вроде "1500 метров"; месяцы и
+	// века - целыми словами, иначе "мая" нашлось бы в "самая". \b в RE2 понимает
+	// только ASCII, поэтому границы слов заданы явно.
+	datePattern = regexp.MustCompile(`(?i)(?:^|[^\p{L}\p{N}])(?:` + strings.Join([]string{
+		`(?:1[0-9]|20)\d{2}(?:-(?:м|го|х|е))?\s*(?:год\p{L}*|г\.|гг\.?|н\.\s?э\.|ad|bc|bce|ce)(?:$|[^\p{L}])`,
+		`(?:1[0-9]|20)\d{2}-(?:м|го|х|е|s)(?:$|[^\p{L}])`,
+		`(?:in|since|by|until|till|from|before|after|around|circa|during|year)\s+(?:1[0-9]|20)\d{2}(?:$|[^\p{L}\p{N}])`,
+		`(?:1[0-9]|20)\d{2}\s*[-–—]\s*(?:1[0-9]|20)\d{2}(?:$|[^\p{L}\p{N}])`,
+		`\d{1,2}[./]\d{1,2}[./]\d{2,4}(?:$|[^\p{N}])`,
+		`(?:january|february|april|june|july|august|september|october|november|december)(?:$|[^\p{L}])`,
+		`(?:march|may)\s+\d|\d{1,2}\s+(?:march|may)(?:$|[^\p{L}])`,
+		`(?:январ|феврал|апрел|июн|июл|сентябр|октябр|ноябр|декабр)(?:ь|я|е|ем|ём)(?:$|[^\p{L}])`,
+		`(?:март|август)(?:а|е|ом)?(?:$|[^\p{L}])`,
+		`ма(?:й|я|е|ем|ём)(?:$|[^\p{L}])`,
+		`век(?:а|е|ов|ах|ами)?(?:$|[^\p{L}])`,
+		`centur(?:y|ies)(?:$|[^\p{L}])`,
+	}, "|") + `)`)
+	numericPattern = regexp.MustCompile(`(?i)\d|%|\b(?:million|billion|thousand|percent|dozen|hundred)s?\b|(?:миллион|миллиард|тысяч|процент|сотн|десят)`)
+)
+
+// ClassifyClaimType определяет тип утверждения по тексту
+func ClassifyClaimType(r FactCheckResult) string {
+	switch {
+	case r.Verifier == "gocode":
+		return ClaimTypeCode
+	case datePattern.MatchString(r.Claim):
+		return ClaimTypeDate
+	case numericPattern.MatchString(r.Claim):
+		return ClaimTypeNumeric
+	case hasNamedEntity(r.Claim):
+		return ClaimTypeEntity
+	}
+	return ClaimTypeGeneral
+}
+
+// hasNamedEntity - есть ли слово с заглавной буквы не в начале предложения
+func hasNamedEntity(claim string) bool {
+	words := strings.Fields(claim)
+	for i, w := range words {
+		if i == 0 || strings.HasSuffix(words[i-1], ".") {
+			continue
+		}
+		first := []rune(strings.Trim(w, "\"«»()"))
+		if len(first) > 1 && unicode.IsUpper(first[0]) {
+			return true
+		}
+	}
+	return false
+}
+
+// claimTypeLabel - название типа утверждения для отчётов
+func claimTypeLabel(claimType string) string {
+	switch claimType {
+	case ClaimTypeCode:
+		return "код"
+	case ClaimTypeDate:
+		return "даты"
+	case ClaimTypeNumeric:
+		return "числа"
+	case ClaimTypeEntity:
+		return "имена"
+	case ClaimTypeGeneral:
+		return "общие"
+	}
+	return "без типа"
+}
+
+// AssignClaimTypes проставляет тип всем результатам
+func AssignClaimTypes(results []FactCheckResult) {
+	for i := range results {
+		results[i].ClaimType = ClassifyClaimType(results[i])
+	}
+}
@@ -4,7 +4,9 @@ package main
 
 import (
 	"fmt"
+	"slices"
 	"strings"
+	"unicode"
 
 	"github.com/lucasb-eyer/go-colorful"
 	"github.com/muesli/termenv"
@@ -33,7 +35,7 @@ func printGradientLogo() {
 	fmt.Println()
 }
 
-func printResults(claimsData ClaimsData, results []FactCheckResult) {
+func printResults(analysis *AnalysisResult) {
 	p := termenv.ColorProfile()
 	colorHeader := p.Color("#00BFFF")
 	colorOk := p.Color("#3FB950")
@@ -47,45 +49,520 @@ func printResults(claimsData ClaimsData, results []FactCheckResult) {
 	fmt.Println(termenv.String("            РЕЗУЛЬТАТЫ ПРОВЕРКИ             ").Foreground(colorText))
 	fmt.Println(termenv.String("  ══════════════════════════════════════════").Foreground(colorHeader))
 
-	fmt.Printf("\n  💬 Ответ: %s\n", claimsData.Response)
+	if analysis.Query != "" {
+		fmt.Printf("\n  ❓ Вопрос: %s\n", analysis.Query)
+	}
+	fmt.Print("\n  💬 Ответ: ")
+	printAnnotatedResponse(analysis)
 	fmt.Println(termenv.String("\n  ──────────────────────────────────────────").Foreground(colorDim))
 
-	for i, result := range results {
-		fmt.Printf("\n  [%d] %s\n", i+1, result.Claim)
+	for i, result := range analysis.FactCheckResults {
+		fmt.Printf("\n  [%d] %s\n", i+1, highlightMismatches(result.Claim, result.Mismatches, colorErr))
 
-		if result.Found && result.Result {
-			fmt.Println(termenv.String(fmt.Sprintf("      ✅ ФАКТ ПОДТВЕРЖДЁН (достоверность: %.0f%%)", result.Factuality*100)).Foreground(colorOk))
-		} else if result.Found && !result.Result {
-			fmt.Println(termenv.String(fmt.Sprintf("      ❌ ГАЛЛЮЦИНАЦИЯ (достоверность: %.0f%%)", result.Factuality*100)).Foreground(colorErr))
-		} else {
-			fmt.Println(termenv.String("      ⚠️  Не удалось проверить").Foreground(colorWarn))
+		label := fmt.Sprintf("      %s %s", verdictIcon(result.Verdict), verdictLabel(result.Verdict))
+		switch result.Verdict {
+		case VerdictSupported:
+			fmt.Println(termenv.String(fmt.Sprintf("%s (%s)", label, confidenceText(result))).Foreground(colorOk))
+		case VerdictRefuted:
+			fmt.Println(termenv.String(fmt.Sprintf("%s (%s)", label, confidenceText(result))).Foreground(colorErr))
+		case VerdictDisputed:
+			fmt.Println(termenv.String(fmt.Sprintf("%s (%s)", label, confidenceText(result))).Foreground(colorWarn))
+		case VerdictError:
+			fmt.Println(termenv.String(fmt.Sprintf("%s: %s", label, result.Error)).Foreground(colorWarn))
+		default:
+			fmt.Println(termenv.String(label).Foreground(colorDim))
+		}
+
+		if result.Span != nil && result.Span.Sentence != "" && result.Span.Sentence != result.Claim {
+			fmt.Println(termenv.String(fmt.Sprintf("      📍 \"%s\"", result.Span.Sentence)).Foreground(colorDim))
+		}
+
+		if analysis.Relevance != nil && slices.Contains(analysis.Relevance.Unrequested, i+1) {
+			fmt.Println(termenv.String("      ↪ Не относится к вопросу").Foreground(colorWarn))
+		}
+
+		if result.PossiblyOutdated {
+			fmt.Println(termenv.String(fmt.Sprintf("      ⏳ ВОЗМОЖНО УСТАРЕЛО: ответ на %s, источник от %s", result.AsOf, result.EvidenceDate)).Foreground(colorWarn))
+		} else if result.TimeSensitive {
+			fmt.Println(termenv.String("      ⏳ Зависит от времени").Foreground(colorDim))
 		}
 
 		if result.Reason != "" {
 			fmt.Printf("      💬 %s\n", result.Reason)
 		}
+		if len(result.References) > 0 {
+			fmt.Println(termenv.String(fmt.Sprintf("      📚 Источников: %d (за: %d, против: %d, доля подтверждений: %.0f%%)",
+				len(result.References), result.SupportingCount, result.RefutingCount, result.SupportRatio*100)).Foreground(colorDim))
+		}
 		if result.ReviewURL != "" {
 			fmt.Println(termenv.String(fmt.Sprintf("      🔗 %s", result.ReviewURL)).Foreground(colorDim))
 		}
+		if result.SourceDomain != "" {
+			fmt.Println(termenv.String(fmt.Sprintf("      🏛  %s (доверие: %.0f%%)", result.SourceDomain, result.SourceCredibility*100)).Foreground(colorDim))
+		}
 		if result.KeyQuote != "" {
 			fmt.Println(termenv.String(fmt.Sprintf("      📝 \"%s\"", result.KeyQuote)).Foreground(colorDim))
 		}
+		for _, m := range result.Mismatches {
+			fmt.Println(termenv.String(fmt.Sprintf("      🔍 %s", mismatchText(m))).Foreground(colorErr))
+		}
+	}
+
+	if analysis.Relevance != nil {
+		printRelevance(*analysis.Relevance)
 	}
 
-	summary := BuildSummary(results)
+	summary := analysis.Summary
 
 	fmt.Println()
 	fmt.Println(termenv.String("  ══════════════════════════════════════════").Foreground(colorHeader))
 	fmt.Println(termenv.String("                   СВОДКА                   ").Foreground(colorText))
 	fmt.Println(termenv.String("  ══════════════════════════════════════════").Foreground(colorHeader))
 	fmt.Printf("  📊 Всего утверждений:      %d\n", summary.TotalClaims)
-	fmt.Println(termenv.String(fmt.Sprintf("  ✅ Подтверждено:            %d", summary.ClaimsFound)).Foreground(colorOk))
-	fmt.Println(termenv.String(fmt.Sprintf("  ❌ Не подтверждено:         %d", summary.ClaimsNotFound)).Foreground(colorErr))
+	fmt.Println(termenv.String(fmt.Sprintf("  ✅ Подтверждено:            %d", summary.Supported)).Foreground(colorOk))
+	fmt.Println(termenv.String(fmt.Sprintf("  ❌ Опровергнуто:            %d", summary.Refuted)).Foreground(colorErr))
+	fmt.Println(termenv.String(fmt.Sprintf("  ⚖️  Спорно:                  %d", summary.Disputed)).Foreground(colorWarn))
+	fmt.Println(termenv.String(fmt.Sprintf("  ❔ Непроверяемо:            %d", summary.Unverifiable)).Foreground(colorDim))
+	if summary.Errors > 0 {
+		fmt.Println(termenv.String(fmt.Sprintf("  ⚠️  Ошибки проверки:         %d", summary.Errors)).Foreground(colorWarn))
+	}
+	if summary.PossiblyOutdated > 0 {
+		fmt.Println(termenv.String(fmt.Sprintf("  ⏳ Возможно устарело:       %d", summary.PossiblyOutdated)).Foreground(colorWarn))
+	}
+	if checked := summary.Supported + summary.Refuted + summary.Disputed; checked > 0 {
+		fmt.Println(termenv.String(fmt.Sprintf("  🚨 Доля галлюцинаций:       %.1f%% из %d проверенных", summary.HallucinationRate*100, checked)).Foreground(colorErr))
+	}
+
+	printRisk(analysis.Risk)
+	if analysis.Policy != nil {
+		printPolicy(*analysis.Policy)
+	}
+
+	fmt.Println(termenv.String("  ══════════════════════════════════════════").Foreground(colorHeader))
+}
+
+// confidenceText - достоверность утверждения; после калибровки - вместе с исходной оценкой проверщика
+func confidenceText(r FactCheckResult) string {
+	if r.Calibration == "" {
+		return fmt.Sprintf("достоверность: %.0f%%", r.Factuality*100)
+	}
+	return fmt.Sprintf("достоверность: %.0f%% с калибровкой, по оценке %s: %.0f%%", displayFactuality(r)*100, r.Verifier, r.Factuality*100)
+}
+
+// printExplain показывает все источники, найденные для утверждения
+func printExplain(n int, result FactCheckResult) {
+	p := termenv.ColorProfile()
+	colorHeader := p.Color("#00BFFF")
+	colorOk := p.Color("#3FB950")
+	colorErr := p.Color("#FF6B6B")
+	colorDim := p.Color("#8B949E")
+
+	fmt.Println()
+	fmt.Println(termenv.String(fmt.Sprintf("  [%d] %s", n, result.Claim)).Foreground(colorHeader))
+	if result.Reason != "" {
+		fmt.Printf("      💬 %s\n", result.Reason)
+	}
+	for _, m := range result.Mismatches {
+		fmt.Println(termenv.String(fmt.Sprintf("      🔍 %s", mismatchText(m))).Foreground(colorErr))
+	}
+
+	if len(result.References) == 0 {
+		fmt.Println(termenv.String("      Источников нет").Foreground(colorDim))
+		return
+	}
+
+	fmt.Printf("      📚 За: %d, против: %d, доля подтверждений: %.0f%%\n",
+		result.SupportingCount, result.RefutingCount, result.SupportRatio*100)
+
+	for i, ref := range result.References {
+		mark := termenv.String("✅ подтверждает").Foreground(colorOk)
+		if !ref.IsSupportive {
+			mark = termenv.String("❌ опровергает").Foreground(colorErr)
+		}
+		fmt.Printf("\n      %d. %s\n", i+1, mark)
+		fmt.Println(termenv.String(fmt.Sprintf("         🔗 %s", ref.URL)).Foreground(colorDim))
+		if ref.Domain != "" {
+			fmt.Println(termenv.String(fmt.Sprintf("         🏛  %s (доверие: %.0f%%)", ref.Domain, ref.Credibility*100)).Foreground(colorDim))
+		}
+		if ref.KeyQuote != "" {
+			fmt.Println(termenv.String(fmt.Sprintf("         📝 \"%s\"", ref.KeyQuote)).Foreground(colorDim))
+		}
+	}
+}
+
+// highlightMismatches выделяет в тексте утверждения слова, расходящиеся с цитатой
+func highlightMismatches(claim string, mismatches []ClaimMismatch, color termenv.Color) string {
+	if len(mismatches) == 0 {
+		return claim
+	}
+	wrong := make(map[string]bool)
+	for _, m := range mismatches {
+		for _, w := range strings.FieldsFunc(m.Claim, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		}) {
+			wrong[w] = true
+		}
+	}
+
+	var b strings.Builder
+	for _, token := range tokenizeWords(claim) {
+		if wrong[token] {
+			b.WriteString(termenv.String(token).Foreground(color).Bold().Underline().String())
+		} else {
+			b.WriteString(token)
+		}
+	}
+	return b.String()
+}
+
+// printProgress показывает ход проверки по событиям пайплайна
+func printProgress(e AnalysisEvent) {
+	p := termenv.ColorProfile()
+	colorOk := p.Color("#3FB950")
+	colorErr := p.Color("#FF6B6B")
+	colorWarn := p.Color("#D29922")
+	colorDim := p.Color("#8B949E")
+
+	switch e.Type {
+	case EventNote:
+		switch e.Level {
+		case noteOK:
+			fmt.Println(termenv.String("  " + e.Message).Foreground(colorOk))
+		case noteWarn:
+			fmt.Println(termenv.String("  ⚠️  " + e.Message).Foreground(colorWarn))
+		default:
+			fmt.Println("  " + e.Message)
+		}
+
+	case EventExtracted:
+		fmt.Printf("     Извлечено утверждений: %d\n\n", len(e.Claims))
+
+	case EventClaim:
+		r := e.Result
+		// При потоковой проверке общее число утверждений заранее неизвестно
+		counter := fmt.Sprintf("%d/%d", e.Index, e.Total)
+		if e.Total == 0 {
+			counter = fmt.Sprint(e.Index)
+		}
+		line := fmt.Sprintf("   [%s] %s %s", counter, verdictIcon(r.Verdict), r.Claim)
+		switch r.Verdict {
+		case VerdictSupported:
+			fmt.Println(termenv.String(line).Foreground(colorOk))
+		case VerdictRefuted:
+			fmt.Println(termenv.String(line).Foreground(colorErr))
+		case VerdictDisputed, VerdictError:
+			fmt.Println(termenv.String(line).Foreground(colorWarn))
+		default:
+			fmt.Println(termenv.String(line).Foreground(colorDim))
+		}
+	}
+}
+
+// printRelevance показывает, отвечает ли ответ на вопрос, - отдельно от проверки фактов
+func printRelevance(report RelevanceReport) {
+	p := termenv.ColorProfile()
+	colorHeader := p.Color("#00BFFF")
+	colorOk := p.Color("#3FB950")
+	colorWarn := p.Color("#D29922")
+	colorDim := p.Color("#8B949E")
+	colorText := p.Color("#E6EDF3")
+
+	fmt.Println()
+	fmt.Println(termenv.String("  ══════════════════════════════════════════").Foreground(colorHeader))
+	fmt.Println(termenv.String("              РЕЛЕВАНТНОСТЬ                 ").Foreground(colorText))
+	fmt.Println(termenv.String("  ══════════════════════════════════════════").Foreground(colorHeader))
+
+	if report.Addressed {
+		fmt.Println(termenv.String(fmt.Sprintf("  🎯 Ответ по существу вопроса (покрытие: %.0f%%)", report.Score*100)).Foreground(colorOk))
+	} else {
+		fmt.Println(termenv.String(fmt.Sprintf("  🎯 Ответ не отвечает на вопрос (покрытие: %.0f%%)", report.Score*100)).Foreground(colorWarn))
+	}
+	if report.ExpectedType != "" {
+		if report.AnswerFound {
+			fmt.Println(termenv.String(fmt.Sprintf("  ✅ Ожидался ответ типа «%s» — найден", report.ExpectedType)).Foreground(colorDim))
+		} else {
+			fmt.Println(termenv.String(fmt.Sprintf("  ⚠️  Ожидался ответ типа «%s» — не найден", report.ExpectedType)).Foreground(colorWarn))
+		}
+	}
+	if len(report.Unrequested) > 0 {
+		numbers := make([]string, len(report.Unrequested))
+		for i, n := range report.Unrequested {
+			numbers[i] = fmt.Sprintf("[%d]", n)
+		}
+		fmt.Println(termenv.String(fmt.Sprintf("  ↪ Утверждения не по вопросу: %s", strings.Join(numbers, ", "))).Foreground(colorWarn))
+	}
+}
+
+// printTranscriptReport - таблица по ответам диалога и итог по всему диалогу
+func printTranscriptReport(report *TranscriptReport) {
+	p := termenv.ColorProfile()
+	colorHeader := p.Color("#00BFFF")
+	colorOk := p.Color("#3FB950")
+	colorErr := p.Color("#FF6B6B")
+	colorWarn := p.Color("#D29922")
+	colorDim := p.Color("#8B949E")
+	colorText := p.Color("#E6EDF3")
+
+	fmt.Println()
+	fmt.Println(termenv.String("  ══════════════════════════════════════════").Foreground(colorHeader))
+	fmt.Println(termenv.String("               ИТОГИ ДИАЛОГА                ").Foreground(colorText))
+	fmt.Println(termenv.String("  ══════════════════════════════════════════").Foreground(colorHeader))
+
+	for _, t := range report.Turns {
+		fmt.Printf("\n  #%d %s\n", t.Message, truncateText(t.Query, 60))
+		if t.Analysis == nil {
+			fmt.Println(termenv.String(fmt.Sprintf("      ⚠️  %s", t.Error)).Foreground(colorWarn))
+			continue
+		}
+
+		s := t.Analysis.Summary
+		color := colorOk
+		if s.Refuted > 0 {
+			color = colorErr
+		} else if s.Disputed > 0 {
+			color = colorWarn
+		}
+		line := fmt.Sprintf("      утверждений: %d, ✅ %d, ❌ %d, ⚖️  %d, риск: %.0f/100",
+			s.TotalClaims, s.Supported, s.Refuted, s.Disputed, t.Analysis.Risk.Score)
+		if rel := t.Analysis.Relevance; rel != nil && !rel.Addressed {
+			line += ", не по вопросу"
+		}
+		fmt.Println(termenv.String(line).Foreground(color))
+	}
 
-	if summary.TotalClaims > 0 {
-		pct := float64(summary.PotentialHallucinations) / float64(summary.TotalClaims) * 100
-		fmt.Println(termenv.String(fmt.Sprintf("  ⚠️  Возможных галлюцинаций: %d (%.1f%%)", summary.PotentialHallucinations, pct)).Foreground(colorWarn))
+	s := report.Summary
+	fmt.Println()
+	fmt.Printf("  📊 Ответов: %d, утверждений: %d\n", len(report.Turns), s.TotalClaims)
+	fmt.Println(termenv.String(fmt.Sprintf("  ✅ Подтверждено: %d   ❌ Опровергнуто: %d   ⚖️  Спорно: %d   ❔ Непроверяемо: %d",
+		s.Supported, s.Refuted, s.Disputed, s.Unverifiable)).Foreground(colorDim))
+	if checked := s.Supported + s.Refuted + s.Disputed; checked > 0 {
+		fmt.Println(termenv.String(fmt.Sprintf("  🚨 Доля галлюцинаций в диалоге: %.1f%% из %d проверенных", s.HallucinationRate*100, checked)).Foreground(colorErr))
+	}
+	fmt.Printf("  🎯 Наибольший риск ответа: %.0f/100\n", report.MaxRisk)
+	switch report.Policy {
+	case "fail":
+		fmt.Println(termenv.String("  🛑 Политика не пройдена хотя бы в одном ответе").Foreground(colorErr).Bold())
+	case "warn":
+		fmt.Println(termenv.String("  ⚠️  Политика: есть предупреждения").Foreground(colorWarn).Bold())
+	case "pass":
+		fmt.Println(termenv.String("  ✅ Политика пройдена во всех ответах").Foreground(colorOk).Bold())
 	}
+	fmt.Println(termenv.String("  ══════════════════════════════════════════").Foreground(colorHeader))
+}
+
+// printCompareReport - рейтинг ответов, таблица утверждений по моделям и расхождения
+func printCompareReport(report *CompareReport) {
+	p := termenv.ColorProfile()
+	colorHeader := p.Color("#00BFFF")
+	colorOk := p.Color("#3FB950")
+	colorErr := p.Color("#FF6B6B")
+	colorWarn := p.Color("#D29922")
+	colorDim := p.Color("#8B949E")
+	colorText := p.Color("#E6EDF3")
 
+	fmt.Println()
+	fmt.Println(termenv.String("  ══════════════════════════════════════════").Foreground(colorHeader))
+	fmt.Println(termenv.String("             СРАВНЕНИЕ ОТВЕТОВ              ").Foreground(colorText))
 	fmt.Println(termenv.String("  ══════════════════════════════════════════").Foreground(colorHeader))
+	if report.Query != "" {
+		fmt.Println(termenv.String("  ❓ " + truncateText(report.Query, 70)).Foreground(colorDim))
+	}
+
+	fmt.Println(termenv.String("\n  🏆 Рейтинг").Foreground(colorHeader).Bold())
+	for _, label := range report.Ranking {
+		for _, r := range report.Responses {
+			if r.Label != label {
+				continue
+			}
+			s := r.Analysis.Summary
+			color := colorOk
+			if s.Refuted > 0 {
+				color = colorErr
+			} else if s.Disputed > 0 {
+				color = colorWarn
+			}
+			fmt.Printf("  %2d. %-16s ", r.Rank, truncateText(r.Label, 16))
+			fmt.Println(termenv.String(fmt.Sprintf("галлюцинаций: %5.1f%% (%d/%d), риск: %3.0f/100, утверждений: %d",
+				s.HallucinationRate*100, s.Refuted, s.Supported+s.Refuted+s.Disputed, r.Analysis.Risk.Score, s.TotalClaims)).Foreground(color))
+		}
+	}
+	for _, r := range report.Responses {
+		if r.Error != "" {
+			fmt.Println(termenv.String(fmt.Sprintf("   —  %-16s ⚠️  %s", truncateText(r.Label, 16), r.Error)).Foreground(colorWarn))
+		}
+	}
+
+	if len(report.Rows) == 0 {
+		fmt.Println(termenv.String("  ══════════════════════════════════════════").Foreground(colorHeader))
+		return
+	}
+
+	// Ширина столбца - по метке модели, но не меньше самого длинного вердикта
+	widths := make([]int, len(report.Responses))
+	header := fmt.Sprintf("  %-44s", "Утверждение")
+	for i, r := range report.Responses {
+		label := truncateText(r.Label, 12)
+		widths[i] = max(len([]rune(label)), 6)
+		header += fmt.Sprintf(" %-*s", widths[i], label)
+	}
+	fmt.Println(termenv.String("\n  📋 Утверждения по моделям").Foreground(colorHeader).Bold())
+	fmt.Println(termenv.String(header).Foreground(colorDim))
+	for _, row := range report.Rows {
+		marker := "  "
+		if row.Disagree != "" {
+			marker = termenv.String("⚡").Foreground(colorWarn).String()
+		}
+		fmt.Printf("%s%-44s", marker, truncateText(row.Claim, 44))
+		for i, v := range row.Verdicts {
+			color := colorDim
+			switch v {
+			case VerdictSupported:
+				color = colorOk
+			case VerdictRefuted:
+				color = colorErr
+			case VerdictDisputed:
+				color = colorWarn
+			}
+			fmt.Print(" " + termenv.String(fmt.Sprintf("%-*s", widths[i], verdictShort(v))).Foreground(color).String())
+		}
+		fmt.Println()
+	}
+
+	if disagreements := report.Disagreements(); len(disagreements) > 0 {
+		fmt.Println(termenv.String(fmt.Sprintf("\n  ⚡ Модели расходятся (%d)", len(disagreements))).Foreground(colorWarn).Bold())
+		for _, row := range disagreements {
+			fmt.Println(termenv.String(fmt.Sprintf("\n  • %s — %s", truncateText(row.Claim, 70), disagreeLabel(row.Disagree))).Foreground(colorText))
+			for i, claim := range row.Claims {
+				if claim == "" {
+					continue
+				}
+				fmt.Println(termenv.String(fmt.Sprintf("      %s %s: %s", verdictIcon(row.Verdicts[i]), report.Responses[i].Label, truncateText(claim, 70))).Foreground(colorDim))
+			}
+		}
+	}
+	fmt.Println(termenv.String("  ══════════════════════════════════════════").Foreground(colorHeader))
+}
+
+// printRisk показывает итоговый риск ответа и разбивку по факторам
+func printRisk(risk RiskScore) {
+	p := termenv.ColorProfile()
+	colorDim := p.Color("#8B949E")
+
+	color := p.Color("#3FB950")
+	switch risk.Level {
+	case "medium":
+		color = p.Color("#D29922")
+	case "high", "critical":
+		color = p.Color("#FF6B6B")
+	}
+
+	fmt.Println(termenv.String(fmt.Sprintf("  🎯 Риск ответа:            %.0f/100 (%s)", risk.Score, riskLevelLabel(risk.Level))).Foreground(color).Bold())
+	for _, f := range risk.Factors {
+		fmt.Println(termenv.String(fmt.Sprintf("     +%5.1f  %s (%d)", f.Points, f.Name, f.Count)).Foreground(colorDim))
+	}
+}
+
+// printPolicy показывает итог проверки политикой и сработавшие правила
+func printPolicy(report PolicyReport) {
+	p := termenv.ColorProfile()
+	colorOk := p.Color("#3FB950")
+	colorErr := p.Color("#FF6B6B")
+	colorWarn := p.Color("#D29922")
+
+	fmt.Println()
+	switch report.Status {
+	case "fail":
+		fmt.Println(termenv.String(fmt.Sprintf("  🛑 Политика %s: НЕ ПРОЙДЕНА", report.Policy)).Foreground(colorErr).Bold())
+	case "warn":
+		fmt.Println(termenv.String(fmt.Sprintf("  ⚠️  Политика %s: пройдена с предупреждениями", report.Policy)).Foreground(colorWarn).Bold())
+	default:
+		fmt.Println(termenv.String(fmt.Sprintf("  ✅ Политика %s: пройдена", report.Policy)).Foreground(colorOk).Bold())
+	}
+
+	for _, v := range report.Violations {
+		color := colorWarn
+		if v.Severity == SeverityFail {
+			color = colorErr
+		}
+		fmt.Println(termenv.String(fmt.Sprintf("     [%s] %s: %s (значение: %g)", v.Severity, v.Rule, v.Message, v.Actual)).Foreground(color))
+	}
+}
+
+// printAnnotatedResponse выводит ответ с подсветкой фрагментов по вердиктам:
+// красный - опровергнуто, жёлтый - спорно или непроверяемо, зелёный - подтверждено.
+// Сноски [N] ведут к утверждению N в списке ниже.
+func printAnnotatedResponse(analysis *AnalysisResult) {
+	p := termenv.ColorProfile()
+	colorOk := p.Color("#3FB950")
+	colorErr := p.Color("#FF6B6B")
+	colorWarn := p.Color("#D29922")
+	colorDim := p.Color("#8B949E")
+
+	highlighted := false
+	for _, seg := range annotateResponse(analysis.Response, analysis.FactCheckResults) {
+		text := termenv.String(strings.ReplaceAll(seg.Text, "\n", "\n  "))
+		switch seg.Verdict {
+		case VerdictRefuted:
+			text = text.Foreground(colorErr).Underline()
+		case VerdictDisputed, VerdictUnverifiable:
+			text = text.Foreground(colorWarn)
+		case VerdictSupported:
+			text = text.Foreground(colorOk)
+		}
+		if seg.Claim >= 0 {
+			highlighted = true
+		}
+		fmt.Print(text)
+
+		for _, n := range seg.Footnotes {
+			fmt.Print(termenv.String(fmt.Sprintf("[%d]", n)).Foreground(colorDim))
+		}
+	}
+	fmt.Println()
+
+	if highlighted {
+		fmt.Print("     ")
+		fmt.Print(termenv.String("■ опровергнуто  ").Foreground(colorErr))
+		fmt.Print(termenv.String("■ спорно/непроверяемо  ").Foreground(colorWarn))
+		fmt.Println(termenv.String("■ подтверждено").Foreground(colorOk))
+	}
+}
+
+// printCorrection показывает исправленный ответ как пословный diff с исходным
+func printCorrection(original string, correction *CorrectedResponse) {
+	p := termenv.ColorProfile()
+	colorHeader := p.Color("#00BFFF")
+	colorOk := p.Color("#3FB950")
+	colorErr := p.Color("#FF6B6B")
+	colorDim := p.Color("#8B949E")
+
+	mode := "шаблон"
+	if correction.Mode == CorrectionLLM {
+		mode = "Gemini"
+	}
+
+	fmt.Println()
+	fmt.Println(termenv.String(fmt.Sprintf("  ✍️  ИСПРАВЛЕННЫЙ ОТВЕТ (%s, правок: %d)", mode, len(correction.Edits))).Foreground(colorHeader))
+	fmt.Println()
+	fmt.Print("  ")
+	for _, op := range diffText(original, correction.Text) {
+		text := strings.ReplaceAll(op.Text, "\n", "\n  ")
+		switch op.Kind {
+		case diffDelete:
+			fmt.Print(termenv.String("[-" + text + "-]").Foreground(colorErr).CrossOut())
+		case diffInsert:
+			fmt.Print(termenv.String("{+" + text + "+}").Foreground(colorOk))
+		default:
+			fmt.Print(text)
+		}
+	}
+	fmt.Println()
+
+	for _, e := range correction.Edits {
+		action := "пометка"
+		if e.Action == "replace" {
+			action = "замена"
+		}
+		fmt.Println(termenv.String(fmt.Sprintf("     [%d] %s: «%s»", e.Claim, action, e.Evidence)).Foreground(colorDim))
+	}
 }
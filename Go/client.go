@@ -19,10 +19,11 @@ type PythonClient struct {
 
 // ExtractSaveResponse - ответ от /extract-and-save
 type ExtractSaveResponse struct {
-	Success     bool     `json:"success"`
-	Filename    string   `json:"filename"`
-	ClaimsCount int      `json:"claims_count"`
-	Claims      []string `json:"claims"`
+	Success     bool        `json:"success"`
+	Filename    string      `json:"filename"`
+	ClaimsCount int         `json:"claims_count"`
+	Claims      []string    `json:"claims"`
+	Spans       []ClaimSpan `json:"spans,omitempty"`
 }
 
 // NewPythonClient создает новый клиент
@@ -0,0 +1,789 @@
+// Go/codecheck.go
+
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// goFencePattern находит блоки ```go ... ``` в ответе ИИ
+var goFencePattern = regexp.MustCompile("(?s)```(?:golang|go)[ \\t]*\\r?\\n?(.*?)```")
+
+// goTypeIndex - методы и поля типа из исходников пакета
+type goTypeIndex struct {
+	methods  map[string]bool
+	fields   map[string]bool
+	embedded bool // есть встроенные поля/интерфейсы — список членов неполный
+	alias    bool
+	isStruct bool
+}
+
+// goPackageIndex - экспортируемые объявления пакета
+type goPackageIndex struct {
+	name    string
+	members map[string]bool
+	types   map[string]*goTypeIndex
+	results map[string][]string // функция -> типы результатов, объявленные в этом же пакете
+}
+
+// goTypeRef - ссылка на именованный тип пакета
+type goTypeRef struct {
+	pkg  string
+	name string
+}
+
+// GoCodeChecker ищет выдуманные пакеты, функции, методы и поля в Go-коде из ответа ИИ.
+// Импорты разрешаются по стандартной библиотеке (GOROOT) и локальному кешу модулей.
+type GoCodeChecker struct {
+	goroot   string
+	modCache string
+	packages map[string]*goPackageIndex
+}
+
+// NewGoCodeChecker создает проверщик по текущему окружению Go
+func NewGoCodeChecker() *GoCodeChecker {
+	modCache := os.Getenv("GOMODCACHE")
+	if modCache == "" {
+		if gopath := filepath.SplitList(build.Default.GOPATH); len(gopath) > 0 {
+			modCache = filepath.Join(gopath[0], "pkg", "mod")
+		}
+	}
+
+	return &GoCodeChecker{
+		goroot:   build.Default.GOROOT,
+		modCache: modCache,
+		packages: make(map[string]*goPackageIndex),
+	}
+}
+
+// Available сообщает, найдены ли исходники стандартной библиотеки.
+// Без них любой импорт выглядел бы выдуманным, поэтому проверка пропускается.
+func (c *GoCodeChecker) Available() bool {
+	if c.goroot == "" {
+		return false
+	}
+	info, err := os.Stat(filepath.Join(c.goroot, "src", "fmt"))
+	return err == nil && info.IsDir()
+}
+
+// extractGoBlocks вынимает содержимое fenced-блоков с Go-кодом
+func extractGoBlocks(text string) []string {
+	var blocks []string
+	for _, m := range goFencePattern.FindAllStringSubmatch(text, -1) {
+		if code := strings.TrimSpace(m[1]); code != "" {
+			blocks = append(blocks, code)
+		}
+	}
+	return blocks
+}
+
+// parseGoSnippet разбирает фрагмент кода. ИИ часто опускает package или пишет
+// только тело функции, поэтому пробуем по очереди несколько обёрток.
+func parseGoSnippet(src string) (*ast.File, error) {
+	fset := token.NewFileSet()
+	mode := parser.SkipObjectResolution
+
+	f, err := parser.ParseFile(fset, "snippet.go", src, mode)
+	if err == nil {
+		return f, nil
+	}
+	if f, err2 := parser.ParseFile(fset, "snippet.go", "package main\n"+src, mode); err2 == nil {
+		return f, nil
+	}
+	if f, err2 := parser.ParseFile(fset, "snippet.go", "package main\nfunc _() {\n"+src+"\n}", mode); err2 == nil {
+		return f, nil
+	}
+	return nil, err
+}
+
+// CheckResponse проверяет все Go-блоки ответа и возвращает найденные
+// несуществующие идентификаторы как опровергнутые утверждения
+func (c *GoCodeChecker) CheckResponse(response string) []FactCheckResult {
+	blocks := extractGoBlocks(response)
+	if len(blocks) == 0 || !c.Available() {
+		return nil
+	}
+
+	var results []FactCheckResult
+	seen := make(map[string]bool)
+
+	for _, block := range blocks {
+		f, err := parseGoSnippet(block)
+		if err != nil {
+			continue
+		}
+		for _, r := range c.checkFile(f) {
+			if seen[r.Claim] {
+				continue
+			}
+			seen[r.Claim] = true
+			results = append(results, r)
+		}
+	}
+
+	return results
+}
+
+func codeIssue(claim, reason string) FactCheckResult {
+	return FactCheckResult{
+		Claim:      "Go: " + claim,
+		Found:      true,
+		Result:     false,
+		Factuality: 0,
+		Reason:     reason,
+		Confidence: 1,
+		Verifier:   "gocode",
+		Verdict:    VerdictRefuted,
+	}
+}
+
+func (c *GoCodeChecker) checkFile(f *ast.File) []FactCheckResult {
+	var issues []FactCheckResult
+
+	// Имя в коде -> путь импорта
+	imports := make(map[string]string)
+	for _, spec := range f.Imports {
+		importPath := strings.Trim(spec.Path.Value, "`\"")
+		if importPath == "C" {
+			continue
+		}
+
+		idx := c.loadPackage(importPath)
+		if idx == nil {
+			issues = append(issues, codeIssue(
+				fmt.Sprintf("import %q", importPath),
+				"Пакет не найден ни в стандартной библиотеке, ни в локальном кеше модулей",
+			))
+		}
+
+		switch {
+		case spec.Name != nil && (spec.Name.Name == "_" || spec.Name.Name == "."):
+			continue
+		case spec.Name != nil:
+			imports[spec.Name.Name] = importPath
+		case idx != nil && idx.name != "":
+			imports[idx.name] = importPath
+		default:
+			imports[guessPackageName(importPath)] = importPath
+		}
+	}
+
+	local := collectLocalNames(f)
+
+	// Фрагменты без import: fmt, strings и т.п. считаем пакетами стандартной библиотеки
+	resolvePackage := func(name string) (string, bool) {
+		if local[name] {
+			return "", false
+		}
+		if p, ok := imports[name]; ok {
+			return p, true
+		}
+		if len(f.Imports) == 0 && c.stdlibDir(name) != "" {
+			return name, true
+		}
+		return "", false
+	}
+
+	vars := c.inferVarTypes(f, resolvePackage)
+
+	ast.Inspect(f, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.SelectorExpr:
+			ident, ok := node.X.(*ast.Ident)
+			if !ok {
+				return true
+			}
+			sel := node.Sel.Name
+
+			if importPath, ok := resolvePackage(ident.Name); ok {
+				idx := c.loadPackage(importPath)
+				if idx == nil || idx.members[sel] {
+					return true
+				}
+				reason := fmt.Sprintf("В пакете %s нет экспортируемого идентификатора %s", importPath, sel)
+				if !ast.IsExported(sel) {
+					reason = fmt.Sprintf("%s не экспортируется пакетом %s", sel, importPath)
+				}
+				issues = append(issues, codeIssue(ident.Name+"."+sel, reason))
+				return true
+			}
+
+			ref, ok := vars.lookup(ident.Name, ident.Pos())
+			if !ok {
+				return true
+			}
+			ti := c.lookupType(ref)
+			if ti == nil || ti.embedded || ti.alias || ti.methods[sel] || ti.fields[sel] {
+				return true
+			}
+			issues = append(issues, codeIssue(
+				fmt.Sprintf("%s.%s (%s.%s)", ident.Name, sel, path.Base(ref.pkg), ref.name),
+				fmt.Sprintf("У типа %s.%s нет метода или поля %s", ref.pkg, ref.name, sel),
+			))
+
+		case *ast.CompositeLit:
+			ref, ok := typeFromExpr(node.Type, resolvePackage)
+			if !ok {
+				return true
+			}
+			ti := c.lookupType(ref)
+			if ti == nil || !ti.isStruct {
+				return true
+			}
+			for _, elt := range node.Elts {
+				kv, ok := elt.(*ast.KeyValueExpr)
+				if !ok {
+					continue
+				}
+				key, ok := kv.Key.(*ast.Ident)
+				if !ok || ti.fields[key.Name] {
+					continue
+				}
+				issues = append(issues, codeIssue(
+					fmt.Sprintf("%s.%s{%s: ...}", path.Base(ref.pkg), ref.name, key.Name),
+					fmt.Sprintf("У структуры %s.%s нет поля %s", ref.pkg, ref.name, key.Name),
+				))
+			}
+		}
+		return true
+	})
+
+	return issues
+}
+
+// collectLocalNames собирает имена, объявленные в самом фрагменте,
+// чтобы не путать переменную с одноимённым пакетом
+func collectLocalNames(f *ast.File) map[string]bool {
+	names := make(map[string]bool)
+	addIdent := func(e ast.Expr) {
+		if ident, ok := e.(*ast.Ident); ok && ident.Name != "_" {
+			names[ident.Name] = true
+		}
+	}
+
+	ast.Inspect(f, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.AssignStmt:
+			if node.Tok == token.DEFINE {
+				for _, lhs := range node.Lhs {
+					addIdent(lhs)
+				}
+			}
+		case *ast.RangeStmt:
+			if node.Tok == token.DEFINE {
+				addIdent(node.Key)
+				addIdent(node.Value)
+			}
+		case *ast.ValueSpec:
+			for _, name := range node.Names {
+				addIdent(name)
+			}
+		case *ast.Field:
+			for _, name := range node.Names {
+				addIdent(name)
+			}
+		case *ast.TypeSpec:
+			addIdent(node.Name)
+		case *ast.FuncDecl:
+			if node.Recv == nil {
+				addIdent(node.Name)
+			}
+		}
+		return true
+	})
+
+	return names
+}
+
+// varBinding - объявление переменной: блок, где оно видно, место, с которого
+// оно действует, и тип; пустой тип не выведен, но перекрывает внешние объявления
+type varBinding struct {
+	scope ast.Node
+	pos   token.Pos
+	ref   goTypeRef
+}
+
+// varTypes - объявления переменных фрагмента по именам
+type varTypes map[string][]varBinding
+
+// lookup находит тип переменной name, видимой в pos: берётся объявление
+// из самого внутреннего блока, содержащего pos
+func (v varTypes) lookup(name string, pos token.Pos) (goTypeRef, bool) {
+	var best *varBinding
+	for i := range v[name] {
+		b := &v[name][i]
+		if pos < b.scope.Pos() || pos >= b.scope.End() {
+			continue
+		}
+		if _, file := b.scope.(*ast.File); !file && pos < b.pos {
+			continue
+		}
+		if best == nil || b.scope.Pos() > best.scope.Pos() || (b.scope == best.scope && b.pos > best.pos) {
+			best = b
+		}
+	}
+	if best == nil || best.ref.name == "" {
+		return goTypeRef{}, false
+	}
+	return best.ref, true
+}
+
+// inferVarTypes грубо выводит типы локальных переменных: достаточно, чтобы
+// проверить x.Method() после x := pkg.New() или var x pkg.Type. Одно имя
+// в разных функциях и блоках - разные переменные.
+func (c *GoCodeChecker) inferVarTypes(f *ast.File, resolve func(string) (string, bool)) varTypes {
+	vars := make(varTypes)
+
+	exprTypes := func(e ast.Expr) []goTypeRef {
+		e = unwrapAddr(e)
+
+		switch v := e.(type) {
+		case *ast.CompositeLit:
+			if ref, ok := typeFromExpr(v.Type, resolve); ok {
+				return []goTypeRef{ref}
+			}
+		case *ast.CallExpr:
+			if ident, ok := v.Fun.(*ast.Ident); ok && ident.Name == "new" && len(v.Args) == 1 {
+				if ref, ok := typeFromExpr(v.Args[0], resolve); ok {
+					return []goTypeRef{ref}
+				}
+				return nil
+			}
+			sel, ok := v.Fun.(*ast.SelectorExpr)
+			if !ok {
+				return nil
+			}
+			pkgIdent, ok := sel.X.(*ast.Ident)
+			if !ok {
+				return nil
+			}
+			importPath, ok := resolve(pkgIdent.Name)
+			if !ok {
+				return nil
+			}
+			idx := c.loadPackage(importPath)
+			if idx == nil {
+				return nil
+			}
+			var refs []goTypeRef
+			for _, name := range idx.results[sel.Sel.Name] {
+				refs = append(refs, goTypeRef{pkg: importPath, name: name})
+			}
+			return refs
+		}
+		return nil
+	}
+
+	// Объявления в блоке, for, if, switch и теле функции видны только внутри
+	var scopes []ast.Node
+	var stack []ast.Node
+	bind := func(ident *ast.Ident, scope ast.Node, pos token.Pos, ref goTypeRef) {
+		if ident.Name != "_" {
+			vars[ident.Name] = append(vars[ident.Name], varBinding{scope: scope, pos: pos, ref: ref})
+		}
+	}
+	bindExprs := func(lhs []ast.Expr, pos token.Pos, refs func(i int) goTypeRef) {
+		scope := scopes[len(scopes)-1]
+		for i, l := range lhs {
+			if ident, ok := l.(*ast.Ident); ok {
+				bind(ident, scope, pos, refs(i))
+			}
+		}
+	}
+	// define связывает lhs с типами rhs: одно выражение может вернуть несколько значений
+	define := func(lhs []ast.Expr, rhs []ast.Expr, pos token.Pos) {
+		if len(rhs) == 1 {
+			refs := exprTypes(rhs[0])
+			bindExprs(lhs, pos, func(i int) goTypeRef {
+				if i < len(refs) {
+					return refs[i]
+				}
+				return goTypeRef{}
+			})
+			return
+		}
+		bindExprs(lhs, pos, func(i int) goTypeRef {
+			if i < len(rhs) {
+				if refs := exprTypes(rhs[i]); len(refs) > 0 {
+					return refs[0]
+				}
+			}
+			return goTypeRef{}
+		})
+	}
+	bindParams := func(fields *ast.FieldList, body *ast.BlockStmt) {
+		if fields == nil || body == nil {
+			return
+		}
+		for _, field := range fields.List {
+			ref, _ := typeFromExpr(field.Type, resolve)
+			for _, name := range field.Names {
+				bind(name, body, body.Pos(), ref)
+			}
+		}
+	}
+
+	scopes = append(scopes, f)
+	ast.Inspect(f, func(n ast.Node) bool {
+		if n == nil {
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if scopes[len(scopes)-1] == top {
+				scopes = scopes[:len(scopes)-1]
+			}
+			return true
+		}
+		stack = append(stack, n)
+
+		switch node := n.(type) {
+		case *ast.BlockStmt, *ast.ForStmt, *ast.IfStmt, *ast.SwitchStmt, *ast.TypeSwitchStmt,
+			*ast.CaseClause, *ast.CommClause:
+			scopes = append(scopes, node)
+		case *ast.RangeStmt:
+			scopes = append(scopes, node)
+			if node.Tok == token.DEFINE {
+				bindExprs([]ast.Expr{node.Key, node.Value}, node.Body.Pos(), func(int) goTypeRef { return goTypeRef{} })
+			}
+		case *ast.FuncDecl:
+			bindParams(node.Recv, node.Body)
+			bindParams(node.Type.Params, node.Body)
+			bindParams(node.Type.Results, node.Body)
+		case *ast.FuncLit:
+			bindParams(node.Type.Params, node.Body)
+			bindParams(node.Type.Results, node.Body)
+		case *ast.AssignStmt:
+			if node.Tok == token.DEFINE {
+				define(node.Lhs, node.Rhs, node.End())
+			}
+		case *ast.ValueSpec:
+			lhs := make([]ast.Expr, len(node.Names))
+			for i, name := range node.Names {
+				lhs[i] = name
+			}
+			if node.Type != nil {
+				ref, _ := typeFromExpr(node.Type, resolve)
+				bindExprs(lhs, node.End(), func(int) goTypeRef { return ref })
+				return true
+			}
+			define(lhs, node.Values, node.End())
+		}
+		return true
+	})
+
+	return vars
+}
+
+// typeFromExpr распознаёт выражения вида pkg.Type и *pkg.Type
+func typeFromExpr(e ast.Expr, resolve func(string) (string, bool)) (goTypeRef, bool) {
+	if star, ok := e.(*ast.StarExpr); ok {
+		e = star.X
+	}
+	sel, ok := e.(*ast.SelectorExpr)
+	if !ok {
+		return goTypeRef{}, false
+	}
+	ident, ok := sel.X.(*ast.Ident)
+	if !ok {
+		return goTypeRef{}, false
+	}
+	importPath, ok := resolve(ident.Name)
+	if !ok {
+		return goTypeRef{}, false
+	}
+	return goTypeRef{pkg: importPath, name: sel.Sel.Name}, true
+}
+
+func (c *GoCodeChecker) lookupType(ref goTypeRef) *goTypeIndex {
+	idx := c.loadPackage(ref.pkg)
+	if idx == nil {
+		return nil
+	}
+	return idx.types[ref.name]
+}
+
+// guessPackageName - имя пакета по пути импорта, если исходники не найдены
+func guessPackageName(importPath string) string {
+	base := path.Base(importPath)
+	if len(base) > 1 && base[0] == 'v' && strings.Trim(base[1:], "0123456789") == "" {
+		base = path.Base(path.Dir(importPath))
+	}
+	base = strings.TrimPrefix(base, "go-")
+	if i := strings.IndexAny(base, ".-"); i > 0 {
+		base = base[:i]
+	}
+	return base
+}
+
+// locatePackage ищет каталог с исходниками пакета
+func (c *GoCodeChecker) locatePackage(importPath string) string {
+	if dir := c.stdlibDir(importPath); dir != "" {
+		return dir
+	}
+	return c.modCacheDir(importPath)
+}
+
+func (c *GoCodeChecker) stdlibDir(importPath string) string {
+	if c.goroot == "" {
+		return ""
+	}
+	// Стандартная библиотека не содержит точки в первом элементе пути
+	if first, _, _ := strings.Cut(importPath, "/"); strings.Contains(first, ".") {
+		return ""
+	}
+	dir := filepath.Join(c.goroot, "src", filepath.FromSlash(importPath))
+	if hasGoFiles(dir) {
+		return dir
+	}
+	return ""
+}
+
+// modCacheDir перебирает префиксы пути импорта как возможные пути модулей:
+// github.com/a/b/c может лежать в модуле github.com/a/b@vX в подкаталоге c
+func (c *GoCodeChecker) modCacheDir(importPath string) string {
+	if c.modCache == "" {
+		return ""
+	}
+
+	elems := strings.Split(importPath, "/")
+	for n := len(elems); n >= 2; n-- {
+		modPath := strings.Join(elems[:n], "/")
+		parent := filepath.Join(c.modCache, filepath.FromSlash(escapeModulePath(path.Dir(modPath))))
+		matches, _ := filepath.Glob(filepath.Join(parent, escapeModulePath(path.Base(modPath))+"@*"))
+		if len(matches) == 0 {
+			continue
+		}
+
+		sort.Sort(sort.Reverse(sort.StringSlice(matches)))
+		for _, modDir := range matches {
+			dir := filepath.Join(modDir, filepath.FromSlash(strings.Join(elems[n:], "/")))
+			if hasGoFiles(dir) {
+				return dir
+			}
+		}
+	}
+	return ""
+}
+
+// escapeModulePath кодирует заглавные буквы так же, как go mod: "A" -> "!a"
+func escapeModulePath(p string) string {
+	var b strings.Builder
+	for _, r := range p {
+		if unicode.IsUpper(r) {
+			b.WriteByte('!')
+			b.WriteRune(unicode.ToLower(r))
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func hasGoFiles(dir string) bool {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return false
+	}
+	for _, e := range entries {
+		if !e.IsDir() && isPackageSource(e.Name()) {
+			return true
+		}
+	}
+	return false
+}
+
+func isPackageSource(name string) bool {
+	return strings.HasSuffix(name, ".go") && !strings.HasSuffix(name, "_test.go")
+}
+
+// loadPackage строит индекс экспортируемых объявлений пакета по его исходникам.
+// Build-теги не учитываются: объединение всех платформ лишь делает проверку мягче.
+func (c *GoCodeChecker) loadPackage(importPath string) *goPackageIndex {
+	if idx, ok := c.packages[importPath]; ok {
+		return idx
+	}
+
+	dir := c.locatePackage(importPath)
+	if dir == "" {
+		c.packages[importPath] = nil
+		return nil
+	}
+
+	idx := &goPackageIndex{
+		members: make(map[string]bool),
+		types:   make(map[string]*goTypeIndex),
+		results: make(map[string][]string),
+	}
+	typeOf := func(name string) *goTypeIndex {
+		ti, ok := idx.types[name]
+		if !ok {
+			ti = &goTypeIndex{methods: make(map[string]bool), fields: make(map[string]bool)}
+			idx.types[name] = ti
+		}
+		return ti
+	}
+
+	entries, _ := os.ReadDir(dir)
+	fset := token.NewFileSet()
+	for _, e := range entries {
+		if e.IsDir() || !isPackageSource(e.Name()) {
+			continue
+		}
+		f, err := parser.ParseFile(fset, filepath.Join(dir, e.Name()), nil, parser.SkipObjectResolution)
+		if err != nil {
+			continue
+		}
+		if idx.name == "" && f.Name.Name != "main" && f.Name.Name != "documentation" {
+			idx.name = f.Name.Name
+		}
+
+		for _, decl := range f.Decls {
+			switch d := decl.(type) {
+			case *ast.FuncDecl:
+				if d.Recv == nil {
+					idx.members[d.Name.Name] = ast.IsExported(d.Name.Name)
+					idx.results[d.Name.Name] = resultTypeNames(d.Type)
+					continue
+				}
+				if len(d.Recv.List) > 0 {
+					if recv := receiverTypeName(d.Recv.List[0].Type); recv != "" {
+						typeOf(recv).methods[d.Name.Name] = true
+					}
+				}
+
+			case *ast.GenDecl:
+				for _, spec := range d.Specs {
+					switch s := spec.(type) {
+					case *ast.ValueSpec:
+						for _, name := range s.Names {
+							idx.members[name.Name] = ast.IsExported(name.Name)
+						}
+					case *ast.TypeSpec:
+						idx.members[s.Name.Name] = ast.IsExported(s.Name.Name)
+						indexTypeSpec(typeOf(s.Name.Name), s)
+					}
+				}
+			}
+		}
+	}
+
+	// В members остаются только экспортируемые имена
+	for name, exported := range idx.members {
+		if !exported {
+			delete(idx.members, name)
+		}
+	}
+
+	c.packages[importPath] = idx
+	return idx
+}
+
+func indexTypeSpec(ti *goTypeIndex, s *ast.TypeSpec) {
+	if s.Assign.IsValid() {
+		ti.alias = true
+		return
+	}
+
+	switch t := s.Type.(type) {
+	case *ast.StructType:
+		ti.isStruct = true
+		for _, field := range t.Fields.List {
+			if len(field.Names) == 0 {
+				ti.embedded = true
+				if name := receiverTypeName(field.Type); name != "" {
+					ti.fields[name] = true
+				} else if sel, ok := unwrapStar(field.Type).(*ast.SelectorExpr); ok {
+					ti.fields[sel.Sel.Name] = true
+				}
+				continue
+			}
+			for _, name := range field.Names {
+				ti.fields[name.Name] = true
+			}
+		}
+	case *ast.InterfaceType:
+		for _, m := range t.Methods.List {
+			if len(m.Names) == 0 {
+				ti.embedded = true
+				continue
+			}
+			for _, name := range m.Names {
+				ti.methods[name.Name] = true
+			}
+		}
+	case *ast.Ident, *ast.SelectorExpr:
+		// type T U — методы U не наследуются, но поля структуры U доступны
+		ti.embedded = true
+	}
+}
+
+// resultTypeNames - имена типов результатов функции; "" для прочих типов
+func resultTypeNames(ft *ast.FuncType) []string {
+	if ft.Results == nil {
+		return nil
+	}
+	var names []string
+	for _, field := range ft.Results.List {
+		name := ""
+		if ident, ok := unwrapStar(field.Type).(*ast.Ident); ok && ast.IsExported(ident.Name) {
+			name = ident.Name
+		}
+		count := len(field.Names)
+		if count == 0 {
+			count = 1
+		}
+		for i := 0; i < count; i++ {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// receiverTypeName достаёт имя типа из T, *T, T[K], *T[K, V]
+func receiverTypeName(e ast.Expr) string {
+	e = unwrapStar(e)
+	switch t := e.(type) {
+	case *ast.IndexExpr:
+		e = t.X
+	case *ast.IndexListExpr:
+		e = t.X
+	}
+	if ident, ok := e.(*ast.Ident); ok {
+		return ident.Name
+	}
+	return ""
+}
+
+// unwrapAddr снимает скобки и взятие адреса: (&pkg.T{}) -> pkg.T{}
+func unwrapAddr(e ast.Expr) ast.Expr {
+	for {
+		switch v := e.(type) {
+		case *ast.ParenExpr:
+			e = v.X
+		case *ast.UnaryExpr:
+			if v.Op != token.AND {
+				return e
+			}
+			e = v.X
+		default:
+			return e
+		}
+	}
+}
+
+func unwrapStar(e ast.Expr) ast.Expr {
+	if star, ok := e.(*ast.StarExpr); ok {
+		return star.X
+	}
+	return e
+}
@@ -0,0 +1,111 @@
+// Go/codecheck_test.go
+
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestCodeCheckVarScopes - одно имя в разных функциях и блоках - разные
+// переменные: тип, выведенный в одной, не должен проверяться в другой
+func TestCodeCheckVarScopes(t *testing.T) {
+	checker := NewGoCodeChecker()
+	if !checker.Available() {
+		t.Skip("исходники стандартной библиотеки недоступны")
+	}
+
+	tests := []struct {
+		name  string
+		code  string
+		wants []string
+	}{
+		{
+			name: "параметр и переменная другой функции",
+			code: `package main
+
+import (
+	"bufio"
+	"net/http"
+	"os"
+)
+
+func h(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte(r.URL.Path))
+}
+
+func read(f *os.File) {
+	r := bufio.NewReader(f)
+	r.ReadString('\n')
+}`,
+		},
+		{
+			name: "перекрытие во вложенном блоке",
+			code: `package main
+
+import (
+	"bufio"
+	"net/http"
+	"os"
+)
+
+func h(r *http.Request, f *os.File) {
+	if r.Method == "POST" {
+		r := bufio.NewReader(f)
+		r.ReadString('\n')
+	}
+	_ = r.URL
+}`,
+		},
+		{
+			name: "поле структуры не переменная",
+			code: `package main
+
+import (
+	"bufio"
+	"net/http"
+)
+
+type conn struct {
+	r *bufio.Reader
+}
+
+func h(w http.ResponseWriter, r *http.Request) {
+	_ = r.URL
+}`,
+		},
+		{
+			name: "выдуманный метод находится",
+			code: `package main
+
+import (
+	"bufio"
+	"os"
+)
+
+func read(f *os.File) {
+	r := bufio.NewReader(f)
+	_ = r.URL
+}`,
+			wants: []string{"У типа bufio.Reader нет метода или поля URL"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := checker.CheckResponse("```go\n" + tt.code + "\n```")
+			var reasons []string
+			for _, r := range results {
+				reasons = append(reasons, r.Reason)
+			}
+			if len(reasons) != len(tt.wants) {
+				t.Fatalf("замечания %q, ожидались %q", reasons, tt.wants)
+			}
+			for i, want := range tt.wants {
+				if !strings.Contains(reasons[i], want) {
+					t.Errorf("замечание %q, ожидалось %q", reasons[i], want)
+				}
+			}
+		})
+	}
+}
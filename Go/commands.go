@@ -0,0 +1,632 @@
+// Go/commands.go
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/muesli/termenv"
+)
+
+// Коды выхода неинтерактивного режима
+const (
+	exitOK         = 0
+	exitPolicyFail = 1
+	exitError      = 2
+)
+
+// runCommand выполняет подкоманду без REPL и возвращает код выхода
+func runCommand(args []string) int {
+	switch args[0] {
+	case "check":
+		return runCheckCommand(args[1:])
+	case "transcript":
+		return runTranscriptCommand(args[1:])
+	case "stream":
+		return runStreamCommand(args[1:])
+	case "compare":
+		return runCompareCommand(args[1:])
+	case "eval":
+		return runEvalCommand(args[1:])
+	case "calibrate":
+		return runCalibrateCommand(args[1:])
+	case "stats":
+		return runStatsCommand(args[1:])
+	case "serve":
+		return runServeCommand(args[1:])
+	case "proxy":
+		return runProxyCommand(args[1:])
+	case "help", "-h", "--help":
+		printHelp(termenv.ColorProfile())
+		return exitOK
+	}
+
+	fmt.Fprintf(os.Stderr, "Неизвестная команда: %s\n", args[0])
+	return exitError
+}
+
+// runCheckCommand - полный пайплайн для CI: ответ из -r, итог по политике
+func runCheckCommand(args []string) int {
+	fs := flag.NewFlagSet("check", flag.ContinueOnError)
+	response := fs.String("r", "", "ответ ИИ для проверки")
+	query := fs.String("q", "", "вопрос пользователя, на который дан ответ")
+	file := fs.String("f", "", "прочитать ответ из файла; \"-\" - из stdin")
+	asOf := fs.String("asof", "", "дата проверки зависящих от времени утверждений (YYYY[-MM[-DD]])")
+	policyPath := fs.String("policy", policyFile, "файл политики; пустая строка - без политики")
+	output := fs.String("o", "", "сохранить отчёт (.json, .md или .html)")
+	model := fs.String("model", "", "метка модели, давшей ответ, - для статистики")
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+
+	if *file == "" && *response == "" && stdinPiped() {
+		*file = "-"
+	}
+	if *file != "" {
+		text, err := readInput(*file)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitError
+		}
+		*response = text
+	}
+	if strings.TrimSpace(*response) == "" {
+		fmt.Fprintln(os.Stderr, "Укажите ответ ИИ: check -r \"текст ответа\" или check -f файл")
+		return exitError
+	}
+
+	policy, err := commandPolicy(fs, *policyPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Политика не загружена: %v\n", err)
+		return exitError
+	}
+	opts := checkOptions{Policy: policy, Model: *model}
+	if *asOf != "" {
+		date, err := parseReferenceDate(*asOf)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitError
+		}
+		opts.ReferenceDate = date
+	}
+
+	analysis, err := runFull(*query, *response, opts, termenv.ColorProfile())
+	if err != nil {
+		return exitError
+	}
+
+	if *output != "" {
+		if err := ExportAnalysis(analysis, *output); err != nil {
+			fmt.Fprintf(os.Stderr, "Ошибка экспорта: %v\n", err)
+			return exitError
+		}
+	}
+
+	return analysis.Policy.ExitCode()
+}
+
+// runTranscriptCommand проверяет лог диалога; файл можно указать до или после флагов
+func runTranscriptCommand(args []string) int {
+	fs := flag.NewFlagSet("transcript", flag.ContinueOnError)
+	asOf := fs.String("asof", "", "дата проверки зависящих от времени утверждений (YYYY[-MM[-DD]])")
+	policyPath := fs.String("policy", policyFile, "файл политики; пустая строка - без политики")
+	output := fs.String("o", "", "сохранить отчёт (.json или .md)")
+
+	model := fs.String("model", "", "метка модели, давшей ответ, - для статистики")
+	path := ""
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		path, args = args[0], args[1:]
+	}
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+	if path == "" {
+		path = fs.Arg(0)
+	}
+	if path == "" {
+		fmt.Fprintln(os.Stderr, "Укажите файл диалога: transcript chat.json")
+		return exitError
+	}
+
+	policy, err := commandPolicy(fs, *policyPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Политика не загружена: %v\n", err)
+		return exitError
+	}
+	opts := checkOptions{Policy: policy, Model: *model}
+	if *asOf != "" {
+		date, err := parseReferenceDate(*asOf)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitError
+		}
+		opts.ReferenceDate = date
+	}
+
+	report := runTranscript(path, opts, termenv.ColorProfile())
+	if report == nil {
+		return exitError
+	}
+	printTranscriptReport(report)
+
+	if *output != "" {
+		if err := ExportTranscript(report, *output); err != nil {
+			fmt.Fprintf(os.Stderr, "Ошибка экспорта: %v\n", err)
+			return exitError
+		}
+	}
+
+	return report.ExitCode()
+}
+
+// runStreamCommand проверяет SSE-поток chat/completions по мере поступления,
+// например: curl -N ... | app stream -q "вопрос"
+func runStreamCommand(args []string) int {
+	fs := flag.NewFlagSet("stream", flag.ContinueOnError)
+	query := fs.String("q", "", "вопрос пользователя, на который дан ответ")
+	file := fs.String("f", "-", "файл с SSE-потоком; \"-\" - stdin")
+	asOf := fs.String("asof", "", "дата проверки зависящих от времени утверждений (YYYY[-MM[-DD]])")
+	policyPath := fs.String("policy", policyFile, "файл политики; пустая строка - без политики")
+	output := fs.String("o", "", "сохранить отчёт (.json, .md или .html)")
+	model := fs.String("model", "", "метка модели, давшей ответ, - для статистики")
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+
+	policy, err := commandPolicy(fs, *policyPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Политика не загружена: %v\n", err)
+		return exitError
+	}
+	opts := checkOptions{Policy: policy, Model: *model}
+	if *asOf != "" {
+		date, err := parseReferenceDate(*asOf)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitError
+		}
+		opts.ReferenceDate = date
+	}
+
+	if os.Getenv("GEMINI_API_KEY") == "" || os.Getenv("JINA_API_KEY") == "" {
+		fmt.Fprintln(os.Stderr, "Для проверки нужны GEMINI_API_KEY и JINA_API_KEY")
+		return exitError
+	}
+	ensurePythonAPI()
+	client := NewPythonClient("http://localhost:8000")
+	if err := client.HealthCheck(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+
+	in := os.Stdin
+	if *file != "-" {
+		f, err := os.Open(*file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "не удалось открыть файл: %v\n", err)
+			return exitError
+		}
+		defer f.Close()
+		in = f
+	}
+
+	tokens := make(chan string)
+	readErr := make(chan error, 1)
+	go func() {
+		readErr <- ReadChatStream(in, tokens)
+	}()
+
+	analysis, err := attachHistory(NewAnalyzer(client, os.Getenv("JINA_API_KEY"))).AnalyzeStream(*query, tokens, opts, printProgress)
+	if rerr := <-readErr; rerr != nil {
+		fmt.Fprintln(os.Stderr, rerr)
+		return exitError
+	}
+	if errors.Is(err, errNoClaims) {
+		fmt.Fprintln(os.Stderr, "Утверждений не найдено")
+		return exitError
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+	printResults(analysis)
+
+	if *output != "" {
+		if err := ExportAnalysis(analysis, *output); err != nil {
+			fmt.Fprintf(os.Stderr, "Ошибка экспорта: %v\n", err)
+			return exitError
+		}
+	}
+
+	return analysis.Policy.ExitCode()
+}
+
+// runCompareCommand проверяет ответы разных моделей на один вопрос и ранжирует их:
+// compare -q "вопрос" gpt=a.txt claude=b.txt или compare -f answers.jsonl
+func runCompareCommand(args []string) int {
+	fs := flag.NewFlagSet("compare", flag.ContinueOnError)
+	query := fs.String("q", "", "вопрос, на который отвечали модели")
+	file := fs.String("f", "", "JSONL с ответами: {\"model\": ..., \"response\": ...}")
+	asOf := fs.String("asof", "", "дата проверки зависящих от времени утверждений (YYYY[-MM[-DD]])")
+	policyPath := fs.String("policy", policyFile, "файл политики; пустая строка - без политики")
+	output := fs.String("o", "", "сохранить отчёт (.json или .md)")
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+
+	responses, err := LoadCompareFiles(fs.Args())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+	if *file != "" {
+		fromFile, fileQuery, err := LoadCompareJSONL(*file)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitError
+		}
+		responses = append(responses, fromFile...)
+		if *query == "" {
+			*query = fileQuery
+		}
+	}
+	if err := validateCompare(responses); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, "Пример: compare -q \"вопрос\" gpt=a.txt claude=b.txt")
+		return exitError
+	}
+
+	policy, err := commandPolicy(fs, *policyPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Политика не загружена: %v\n", err)
+		return exitError
+	}
+	opts := checkOptions{Policy: policy}
+	if *asOf != "" {
+		date, err := parseReferenceDate(*asOf)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitError
+		}
+		opts.ReferenceDate = date
+	}
+
+	report := runCompare(*query, responses, opts, termenv.ColorProfile())
+	printCompareReport(report)
+
+	if *output != "" {
+		if err := ExportCompare(report, *output); err != nil {
+			fmt.Fprintf(os.Stderr, "Ошибка экспорта: %v\n", err)
+			return exitError
+		}
+	}
+
+	return report.ExitCode()
+}
+
+// runEvalCommand оценивает конфигурацию проверки на размеченном наборе:
+// eval набор.jsonl [флаги], eval runs - сохранённые прогоны, eval diff A B - сравнение
+func runEvalCommand(args []string) int {
+	if len(args) > 0 {
+		switch args[0] {
+		case "runs":
+			return runEvalRunsCommand()
+		case "diff":
+			return runEvalDiffCommand(args[1:])
+		}
+	}
+
+	fs := flag.NewFlagSet("eval", flag.ContinueOnError)
+	verifiers := fs.String("verifiers", evalVerifierMyths+","+evalVerifierJina, "цепочка проверщиков по порядку: myths, jina")
+	thresholds := fs.String("thresholds", verdictsFile, "файл порогов вердиктов")
+	sources := fs.String("sources", sourcesFile, "файл доверия к источникам")
+	myths := fs.String("myths", misconceptionsFile, "база заблуждений")
+	calibration := fs.String("calibration", calibrationFile, "калибровка уверенности; пустая строка - без неё")
+	cache := fs.String("cache", evalCacheFile, "кэш ответов Jina")
+	offline := fs.Bool("offline", false, "не ходить в сеть: Jina только из кэша")
+	asOf := fs.String("asof", "", "дата проверки зависящих от времени утверждений (YYYY[-MM[-DD]])")
+	name := fs.String("name", "", "название прогона для списка")
+	output := fs.String("o", "", "сохранить отчёт (.json)")
+
+	path := ""
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		path, args = args[0], args[1:]
+	}
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+	if path == "" {
+		path = fs.Arg(0)
+	}
+	if path == "" {
+		fmt.Fprintln(os.Stderr, "Укажите размеченный набор: eval набор.jsonl")
+		return exitError
+	}
+
+	config := EvalConfig{Thresholds: *thresholds, Sources: *sources, Myths: *myths, Calibration: *calibration, Cache: *cache, Offline: *offline}
+	var err error
+	if config.Verifiers, err = parseEvalVerifiers(*verifiers); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+	if *asOf != "" {
+		if config.ReferenceDate, err = parseReferenceDate(*asOf); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitError
+		}
+	}
+
+	items, err := LoadEvalDataset(path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+	report, err := RunEval(path, items, config, os.Getenv("JINA_API_KEY"), func(done, total int) {
+		fmt.Fprintf(os.Stderr, "\r  Проверено %d из %d", done, total)
+	})
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+	report.Name = *name
+
+	if history, err := sharedHistory(); err != nil {
+		fmt.Fprintf(os.Stderr, "  ⚠️  Прогон не сохранён: %v\n", err)
+	} else if _, err := history.SaveEvalRun(report); err != nil {
+		fmt.Fprintf(os.Stderr, "  ⚠️  Прогон не сохранён: %v\n", err)
+	}
+	printEvalReport(report)
+
+	if *output != "" {
+		if err := ExportEvalReport(report, *output); err != nil {
+			fmt.Fprintf(os.Stderr, "Ошибка экспорта: %v\n", err)
+			return exitError
+		}
+	}
+	return exitOK
+}
+
+// runEvalRunsCommand - список сохранённых прогонов оценки
+func runEvalRunsCommand() int {
+	history, err := sharedHistory()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "История недоступна: %v\n", err)
+		return exitError
+	}
+	runs, err := history.EvalRuns(defaultHistoryLimit)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+	printEvalRuns(runs)
+	return exitOK
+}
+
+// runEvalDiffCommand сравнивает два сохранённых прогона: eval diff 3 5
+func runEvalDiffCommand(args []string) int {
+	if len(args) != 2 {
+		fmt.Fprintln(os.Stderr, "Укажите два прогона: eval diff 3 5")
+		return exitError
+	}
+	history, err := sharedHistory()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "История недоступна: %v\n", err)
+		return exitError
+	}
+
+	var reports [2]*EvalReport
+	for i, arg := range args {
+		id, err := strconv.ParseInt(strings.TrimPrefix(arg, "#"), 10, 64)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Номер прогона должен быть числом: %s\n", arg)
+			return exitError
+		}
+		if reports[i], err = history.EvalRun(id); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitError
+		}
+	}
+	printEvalDiff(reports[0], reports[1])
+	return exitOK
+}
+
+// runCalibrateCommand - оценки рецензентов и калибровка уверенности проверщиков:
+// calibrate review, calibrate label 12 3 верно, calibrate fit, calibrate show
+func runCalibrateCommand(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "Укажите действие: calibrate review | label <запись> <N> верно|неверно | fit | show")
+		return exitError
+	}
+	history, err := sharedHistory()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "История недоступна: %v\n", err)
+		return exitError
+	}
+
+	switch args[0] {
+	case "review":
+		fs := flag.NewFlagSet("calibrate review", flag.ContinueOnError)
+		limit := fs.Int("n", defaultHistoryLimit, "сколько вердиктов показать")
+		if err := fs.Parse(args[1:]); err != nil {
+			return exitError
+		}
+		labeled, err := runCalibrationReview(history, *limit, bufio.NewScanner(os.Stdin), termenv.ColorProfile())
+		fmt.Printf("\n  Оценено вердиктов: %d\n", labeled)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitError
+		}
+
+	case "label":
+		if len(args) != 4 {
+			fmt.Fprintln(os.Stderr, "Пример: calibrate label 12 3 верно")
+			return exitError
+		}
+		id, err1 := strconv.ParseInt(strings.TrimPrefix(args[1], "#"), 10, 64)
+		position, err2 := strconv.Atoi(args[2])
+		correct, ok := parseLabel(args[3])
+		if err1 != nil || err2 != nil || !ok {
+			fmt.Fprintln(os.Stderr, "Пример: calibrate label 12 3 верно")
+			return exitError
+		}
+		if err := history.LabelClaim(id, position, correct); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitError
+		}
+
+	case "fit":
+		fs := flag.NewFlagSet("calibrate fit", flag.ContinueOnError)
+		method := fs.String("method", CalibrationAuto, "isotonic, platt или auto (isotonic от 100 меток)")
+		minSamples := fs.Int("min", minCalibrationSamples, "меньше меток у проверщика - без калибровки")
+		output := fs.String("o", calibrationFile, "куда сохранить калибровку")
+		if err := fs.Parse(args[1:]); err != nil {
+			return exitError
+		}
+		switch *method {
+		case CalibrationAuto, CalibrationIsotonic, CalibrationPlatt:
+		default:
+			fmt.Fprintf(os.Stderr, "Неизвестный метод %q: isotonic, platt или auto\n", *method)
+			return exitError
+		}
+
+		samples, err := history.CalibrationSamples()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitError
+		}
+		calibration, skipped := FitCalibration(samples, *method, *minSamples)
+		printCalibration(calibration, skipped)
+		if len(calibration.Models) == 0 {
+			fmt.Fprintln(os.Stderr, "Не хватает оценок ни для одного проверщика: calibrate review")
+			return exitError
+		}
+		if err := calibration.Save(*output); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitError
+		}
+		fmt.Printf("\n  ✅ Калибровка сохранена в %s\n", *output)
+
+	case "show":
+		calibration, err := LoadCalibration(calibrationFile)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitError
+		}
+		printCalibration(calibration, nil)
+
+	default:
+		fmt.Fprintf(os.Stderr, "Неизвестное действие: %s\n", args[0])
+		return exitError
+	}
+	return exitOK
+}
+
+// commandPolicy загружает политику команды. Явно указанный -policy должен
+// существовать; испорченный файл - ошибка в любом случае, а не предупреждение
+func commandPolicy(fs *flag.FlagSet, path string) (*Policy, error) {
+	if path == "" {
+		return nil, nil
+	}
+	explicit := false
+	fs.Visit(func(f *flag.Flag) {
+		explicit = explicit || f.Name == "policy"
+	})
+	if explicit {
+		return LoadRequiredPolicy(path)
+	}
+	return LoadPolicy(path)
+}
+
+// runStatsCommand - статистика по истории проверок
+func runStatsCommand(args []string) int {
+	fs := flag.NewFlagSet("stats", flag.ContinueOnError)
+	days := fs.Int("days", defaultStatsDays, "за сколько последних дней")
+	model := fs.String("model", "", "только ответы этой модели")
+	csvPath := fs.String("csv", "", "выгрузить в CSV; \"-\" - в stdout вместо отчёта")
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+
+	if err := runStats(*days, *model, *csvPath, termenv.ColorProfile()); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+	return exitOK
+}
+
+// runServeCommand поднимает REST API; работает до Ctrl+C или SIGTERM
+func runServeCommand(args []string) int {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	addr := fs.String("addr", defaultServeAddr, "адрес HTTP-сервера")
+	policy := fs.String("policy", policyFile, "файл политики; пустая строка - без политики")
+	jobs := fs.String("jobs", jobsDir, "каталог фоновых задач")
+	workers := fs.Int("workers", defaultJobWorkers, "сколько задач выполняется одновременно")
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+
+	if os.Getenv("GEMINI_API_KEY") == "" || os.Getenv("JINA_API_KEY") == "" {
+		fmt.Fprintln(os.Stderr, "Для сервера нужны GEMINI_API_KEY и JINA_API_KEY")
+		return exitError
+	}
+
+	ensurePythonAPI()
+	client := NewPythonClient("http://localhost:8000")
+	if err := client.HealthCheck(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+
+	server := NewServer(attachHistory(NewAnalyzer(client, os.Getenv("JINA_API_KEY"))), *policy)
+	if err := server.StartJobs(*jobs, *workers); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+	if err := server.ListenAndServe(*addr); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+	return exitOK
+}
+
+// runProxyCommand поднимает OpenAI-совместимый прокси с проверкой ответов
+func runProxyCommand(args []string) int {
+	fs := flag.NewFlagSet("proxy", flag.ContinueOnError)
+	addr := fs.String("addr", ":8081", "адрес прокси")
+	upstream := fs.String("upstream", os.Getenv("LLM_UPSTREAM_URL"), "базовый URL провайдера (по умолчанию $LLM_UPSTREAM_URL)")
+	policy := fs.String("policy", policyFile, "файл политики; пустая строка - без политики")
+	block := fs.Bool("block", false, "отклонять ответы, не прошедшие политику")
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+
+	if os.Getenv("GEMINI_API_KEY") == "" || os.Getenv("JINA_API_KEY") == "" {
+		fmt.Fprintln(os.Stderr, "Для прокси нужны GEMINI_API_KEY и JINA_API_KEY")
+		return exitError
+	}
+
+	ensurePythonAPI()
+	client := NewPythonClient("http://localhost:8000")
+	if err := client.HealthCheck(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+
+	proxy, err := NewProxy(*upstream, attachHistory(NewAnalyzer(client, os.Getenv("JINA_API_KEY"))), *policy, *block)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+	if err := proxy.ListenAndServe(*addr); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+	return exitOK
+}
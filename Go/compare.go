@@ -0,0 +1,341 @@
+// Go/compare.go
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/muesli/termenv"
+)
+
+// compareClaimThreshold - сходство слов (коэффициент Дайса), при котором
+// утверждения разных ответов считаются одним и тем же утверждением
+const compareClaimThreshold = 0.6
+
+// Причины расхождения моделей в одном утверждении
+const (
+	DisagreeVerdict = "verdict" // одна модель права, другая нет
+	DisagreeNumbers = "numbers" // модели называют разные числа или даты
+)
+
+// CompareResponse - один из сравниваемых ответов. Label - метка модели;
+// Rank - место в рейтинге (с 1), 0 - если проверка не выполнена.
+type CompareResponse struct {
+	Label    string          `json:"label"`
+	Response string          `json:"response"`
+	Analysis *AnalysisResult `json:"analysis,omitempty"`
+	Error    string          `json:"error,omitempty"`
+	Rank     int             `json:"rank,omitempty"`
+}
+
+// CompareRow - одно утверждение во всех ответах: Claims[i] и Verdicts[i] -
+// формулировка и вердикт в ответе i, пустые, если модель этого не утверждала
+type CompareRow struct {
+	Claim    string    `json:"claim"`
+	Claims   []string  `json:"claims"`
+	Verdicts []Verdict `json:"verdicts"`
+	Disagree string    `json:"disagree,omitempty"`
+
+	words map[string]bool
+}
+
+// CompareReport - сравнение ответов разных моделей на один вопрос.
+// Ranking - метки от лучшего ответа к худшему.
+type CompareReport struct {
+	Query     string            `json:"query"`
+	Responses []CompareResponse `json:"responses"`
+	Rows      []CompareRow      `json:"rows"`
+	Ranking   []string          `json:"ranking"`
+}
+
+// LoadCompareFiles читает ответы из файлов; метка - "метка=путь" или имя файла без расширения
+func LoadCompareFiles(args []string) ([]CompareResponse, error) {
+	var responses []CompareResponse
+	for _, arg := range args {
+		label, path, ok := strings.Cut(arg, "=")
+		if !ok {
+			path = arg
+			label = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		}
+		text, err := readInput(path)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		responses = append(responses, CompareResponse{Label: label, Response: text})
+	}
+	return responses, nil
+}
+
+// LoadCompareJSONL читает ответы из JSONL: {"model": "...", "response": "..."}
+// на строку; вместо model можно label, вместо response - content. Возвращает
+// также вопрос из поля query, если он есть в файле.
+func LoadCompareJSONL(path string) ([]CompareResponse, string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, "", fmt.Errorf("не удалось прочитать файл: %w", err)
+	}
+
+	var responses []CompareResponse
+	query := ""
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), maxInputSize)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+		var item struct {
+			Model    string `json:"model"`
+			Label    string `json:"label"`
+			Query    string `json:"query"`
+			Response string `json:"response"`
+			Content  string `json:"content"`
+		}
+		if err := json.Unmarshal(text, &item); err != nil {
+			return nil, "", fmt.Errorf("строка %d: ошибка парсинга JSONL: %w", line, err)
+		}
+		label, response := item.Model, item.Response
+		if label == "" {
+			label = item.Label
+		}
+		if label == "" {
+			label = fmt.Sprintf("ответ %d", len(responses)+1)
+		}
+		if response == "" {
+			response = item.Content
+		}
+		if query == "" {
+			query = item.Query
+		}
+		responses = append(responses, CompareResponse{Label: label, Response: response})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, "", fmt.Errorf("ошибка чтения JSONL: %w", err)
+	}
+	return responses, query, nil
+}
+
+// validateCompare - ответов хотя бы два, метки не повторяются, ответы не пустые
+func validateCompare(responses []CompareResponse) error {
+	if len(responses) < 2 {
+		return fmt.Errorf("для сравнения нужно хотя бы два ответа")
+	}
+	seen := make(map[string]bool)
+	for _, r := range responses {
+		if seen[r.Label] {
+			return fmt.Errorf("метка %q повторяется", r.Label)
+		}
+		seen[r.Label] = true
+		if strings.TrimSpace(r.Response) == "" {
+			return fmt.Errorf("ответ %q пустой", r.Label)
+		}
+	}
+	return nil
+}
+
+// runCompare прогоняет полный пайплайн по каждому ответу и сопоставляет результаты
+func runCompare(query string, responses []CompareResponse, opts checkOptions, p termenv.Profile) *CompareReport {
+	colorHeader := p.Color("#00BFFF")
+
+	report := &CompareReport{Query: query}
+	for i := range responses {
+		r := &responses[i]
+		fmt.Println(termenv.String(fmt.Sprintf("\n  ━━━ %s (%d из %d) ━━━", r.Label, i+1, len(responses))).Foreground(colorHeader).Bold())
+
+		modelOpts := opts
+		modelOpts.Model = r.Label
+		analysis, err := runFull(query, r.Response, modelOpts, p)
+		switch {
+		case errors.Is(err, errNoClaims):
+			r.Analysis = noClaimsAnalysis(query, r.Response, modelOpts)
+		case err != nil:
+			r.Error = err.Error()
+		default:
+			r.Analysis = analysis
+		}
+	}
+
+	report.Responses = responses
+	report.Rows = compareRows(responses)
+	report.Ranking = rankResponses(responses)
+	return report
+}
+
+// compareRows сводит утверждения всех ответов в строки: утверждение ответа
+// попадает в самую похожую строку, где этот ответ ещё не представлен
+func compareRows(responses []CompareResponse) []CompareRow {
+	var rows []CompareRow
+	for i, r := range responses {
+		if r.Analysis == nil {
+			continue
+		}
+		for _, result := range r.Analysis.FactCheckResults {
+			words, _ := normalizeClaim(result.Claim)
+			best, bestScore := -1, 0.0
+			for j := range rows {
+				if rows[j].Claims[i] != "" {
+					continue
+				}
+				if score := diceCoefficient(words, rows[j].words); score >= compareClaimThreshold && score > bestScore {
+					best, bestScore = j, score
+				}
+			}
+			if best < 0 {
+				rows = append(rows, CompareRow{
+					Claim:    result.Claim,
+					Claims:   make([]string, len(responses)),
+					Verdicts: make([]Verdict, len(responses)),
+					words:    words,
+				})
+				best = len(rows) - 1
+			}
+			rows[best].Claims[i] = result.Claim
+			rows[best].Verdicts[i] = result.Verdict
+		}
+	}
+
+	for i := range rows {
+		rows[i].Disagree = disagreement(rows[i])
+	}
+	return rows
+}
+
+// disagreement - расходятся ли модели в утверждении: одна подтверждена,
+// другая опровергнута, или при тех же словах названы разные числа
+func disagreement(row CompareRow) string {
+	var right, wrong, stated int
+	var numbers map[string]bool
+	differentNumbers := false
+	for i, claim := range row.Claims {
+		if claim == "" {
+			continue
+		}
+		stated++
+		switch row.Verdicts[i] {
+		case VerdictSupported:
+			right++
+		case VerdictRefuted, VerdictDisputed:
+			wrong++
+		}
+		_, n := normalizeClaim(claim)
+		if numbers == nil {
+			numbers = n
+		} else if !sameSet(numbers, n) {
+			differentNumbers = true
+		}
+	}
+
+	switch {
+	case stated < 2:
+		return ""
+	case right > 0 && wrong > 0:
+		return DisagreeVerdict
+	case differentNumbers:
+		return DisagreeNumbers
+	}
+	return ""
+}
+
+// rankResponses упорядочивает ответы: меньше галлюцинаций, затем больше доля
+// проверенных утверждений, меньше риск и больше подтверждённых. Ответ, в котором
+// ничего не удалось проверить, идёт последним: его нулевая доля галлюцинаций
+// ничего не говорит. Ответы без анализа не ранжируются.
+func rankResponses(responses []CompareResponse) []string {
+	var order []int
+	for i, r := range responses {
+		if r.Analysis != nil {
+			order = append(order, i)
+		}
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		x, y := responses[order[a]].Analysis, responses[order[b]].Analysis
+		cx, cy := verifiedShare(x.Summary), verifiedShare(y.Summary)
+		if (cx == 0) != (cy == 0) {
+			return cx > 0
+		}
+		if x.Summary.HallucinationRate != y.Summary.HallucinationRate {
+			return x.Summary.HallucinationRate < y.Summary.HallucinationRate
+		}
+		if cx != cy {
+			return cx > cy
+		}
+		if x.Risk.Score != y.Risk.Score {
+			return x.Risk.Score < y.Risk.Score
+		}
+		return x.Summary.Supported > y.Summary.Supported
+	})
+
+	ranking := make([]string, len(order))
+	for rank, i := range order {
+		responses[i].Rank = rank + 1
+		ranking[rank] = responses[i].Label
+	}
+	return ranking
+}
+
+// verifiedShare - доля утверждений с вердиктом: подтверждено, опровергнуто или спорно
+func verifiedShare(s ResultSummary) float64 {
+	if s.TotalClaims == 0 {
+		return 0
+	}
+	return float64(s.Supported+s.Refuted+s.Disputed) / float64(s.TotalClaims)
+}
+
+// Disagreements - строки, в которых модели расходятся
+func (r *CompareReport) Disagreements() []CompareRow {
+	var rows []CompareRow
+	for _, row := range r.Rows {
+		if row.Disagree != "" {
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+// ExitCode - exitError, если хоть один ответ не удалось проверить
+func (r *CompareReport) ExitCode() int {
+	for _, resp := range r.Responses {
+		if resp.Error != "" {
+			return exitError
+		}
+	}
+	return exitOK
+}
+
+// disagreeLabel - причина расхождения для отчётов
+func disagreeLabel(kind string) string {
+	switch kind {
+	case DisagreeVerdict:
+		return "вердикты расходятся"
+	case DisagreeNumbers:
+		return "разные числа"
+	}
+	return kind
+}
+
+// verdictShort - вердикт одним словом для таблицы сравнения
+func verdictShort(v Verdict) string {
+	switch v {
+	case "":
+		return "—"
+	case VerdictSupported:
+		return "верно"
+	case VerdictRefuted:
+		return "ложь"
+	case VerdictDisputed:
+		return "спорно"
+	case VerdictUnverifiable:
+		return "н/д"
+	}
+	return "ошибка"
+}
@@ -0,0 +1,310 @@
+// Go/correction.go
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Режимы исправления ответа
+const (
+	CorrectionTemplate = "template" // офлайн: подстановка чисел и пометки по цитатам
+	CorrectionLLM      = "llm"      // переписывание через Gemini
+)
+
+// CorrectionEdit - правка одного опровергнутого фрагмента.
+// Start/End - позиции в символах в исходном ответе.
+type CorrectionEdit struct {
+	Claim       int    `json:"claim"`
+	Start       int    `json:"start"`
+	End         int    `json:"end"`
+	Original    string `json:"original"`
+	Replacement string `json:"replacement"`
+	Action      string `json:"action"` // replace или annotate
+	Evidence    string `json:"evidence"`
+	Citation    int    `json:"citation,omitempty"`
+}
+
+// CorrectedResponse - предложенная исправленная версия ответа
+type CorrectedResponse struct {
+	Mode      string           `json:"mode"`
+	Text      string           `json:"text"`
+	Edits     []CorrectionEdit `json:"edits,omitempty"`
+	Footnotes []string         `json:"footnotes,omitempty"`
+}
+
+// correctionEvidence - чем опровергается утверждение: цитата опровергающего
+// источника, поправка из базы мифов или, в крайнем случае, объяснение проверщика
+func correctionEvidence(r FactCheckResult) (evidence, sourceURL string) {
+	best := -1
+	for i, ref := range r.References {
+		if ref.IsSupportive || ref.KeyQuote == "" {
+			continue
+		}
+		if best < 0 || ref.Credibility > r.References[best].Credibility {
+			best = i
+		}
+	}
+	if best >= 0 {
+		return r.References[best].KeyQuote, r.References[best].URL
+	}
+	if r.MatchedMyth != "" {
+		return strings.TrimPrefix(r.Reason, mythReasonPrefix), r.ReviewURL
+	}
+	if !r.Result && r.KeyQuote != "" {
+		return r.KeyQuote, r.ReviewURL
+	}
+	return r.Reason, r.ReviewURL
+}
+
+// substituteNumber заменяет единственное расходящееся с доказательством число
+// во фрагменте: "битва была в 1480 году" + "...8 сентября 1380 года" -> "...в 1380 году"
+func substituteNumber(fragment, evidence string) (string, bool) {
+	fragNumbers := numberPattern.FindAllString(fragment, -1)
+	evidenceNumbers := numberPattern.FindAllString(evidence, -1)
+	inEvidence := make(map[string]bool)
+	for _, n := range evidenceNumbers {
+		inEvidence[n] = true
+	}
+	inFragment := make(map[string]bool)
+	for _, n := range fragNumbers {
+		inFragment[n] = true
+	}
+
+	var wrong []string
+	for _, n := range fragNumbers {
+		if !inEvidence[n] {
+			wrong = append(wrong, n)
+		}
+	}
+	if len(wrong) != 1 {
+		return "", false
+	}
+
+	// Кандидат на замену - число из доказательства той же разрядности
+	var candidates []string
+	for _, n := range evidenceNumbers {
+		if !inFragment[n] && len(n) == len(wrong[0]) && !slices.Contains(candidates, n) {
+			candidates = append(candidates, n)
+		}
+	}
+	if len(candidates) != 1 {
+		return "", false
+	}
+
+	return strings.Replace(fragment, wrong[0], candidates[0], 1), true
+}
+
+// planCorrections выбирает опровергнутые утверждения с известным местом в ответе
+// и готовит правки; пересекающиеся фрагменты пропускаются
+func planCorrections(analysis *AnalysisResult) ([]CorrectionEdit, []string) {
+	var edits []CorrectionEdit
+	for i, r := range analysis.FactCheckResults {
+		if r.Verdict != VerdictRefuted || r.Span == nil || r.Span.Start == nil || r.Span.End == nil {
+			continue
+		}
+		evidence, _ := correctionEvidence(r)
+		if evidence == "" {
+			continue
+		}
+		original := SpanText(analysis.Response, r.Span)
+		if original == "" {
+			continue
+		}
+		edits = append(edits, CorrectionEdit{
+			Claim:    i + 1,
+			Start:    *r.Span.Start,
+			End:      *r.Span.End,
+			Original: original,
+			Evidence: evidence,
+		})
+	}
+
+	sort.Slice(edits, func(a, b int) bool { return edits[a].Start < edits[b].Start })
+	var planned []CorrectionEdit
+	var footnotes []string
+	lastEnd := -1
+	for _, e := range edits {
+		if e.Start < lastEnd {
+			continue
+		}
+		lastEnd = e.End
+
+		_, sourceURL := correctionEvidence(analysis.FactCheckResults[e.Claim-1])
+		if sourceURL != "" {
+			footnotes = append(footnotes, fmt.Sprintf("%s — «%s»", sourceURL, e.Evidence))
+			e.Citation = len(footnotes)
+		}
+
+		marker := ""
+		if e.Citation > 0 {
+			marker = fmt.Sprintf("[%d]", e.Citation)
+		}
+		if fixed, ok := substituteNumber(e.Original, e.Evidence); ok {
+			e.Action = "replace"
+			e.Replacement = fixed + marker
+		} else {
+			e.Action = "annotate"
+			e.Replacement = fmt.Sprintf("%s [исправление: %s]%s", e.Original, strings.TrimSpace(e.Evidence), marker)
+		}
+		planned = append(planned, e)
+	}
+
+	return planned, footnotes
+}
+
+// CorrectTemplate - офлайн-исправление: правки по цитатам плюс сноски с источниками
+func CorrectTemplate(analysis *AnalysisResult) *CorrectedResponse {
+	edits, footnotes := planCorrections(analysis)
+
+	runes := []rune(analysis.Response)
+	var b strings.Builder
+	pos := 0
+	for _, e := range edits {
+		b.WriteString(string(runes[pos:e.Start]))
+		b.WriteString(e.Replacement)
+		pos = e.End
+	}
+	b.WriteString(string(runes[pos:]))
+
+	return &CorrectedResponse{
+		Mode:      CorrectionTemplate,
+		Text:      appendFootnotes(b.String(), footnotes),
+		Edits:     edits,
+		Footnotes: footnotes,
+	}
+}
+
+func appendFootnotes(text string, footnotes []string) string {
+	if len(footnotes) == 0 {
+		return text
+	}
+	var b strings.Builder
+	b.WriteString(strings.TrimRight(text, "\n"))
+	b.WriteString("\n\n")
+	for i, f := range footnotes {
+		fmt.Fprintf(&b, "[%d] %s\n", i+1, f)
+	}
+	return b.String()
+}
+
+// GeminiRewriter переписывает ответ через Gemini generateContent API
+type GeminiRewriter struct {
+	apiKey     string
+	model      string
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewGeminiRewriter создает клиент; модель можно сменить через GEMINI_REWRITE_MODEL
+func NewGeminiRewriter(apiKey string) *GeminiRewriter {
+	model := os.Getenv("GEMINI_REWRITE_MODEL")
+	if model == "" {
+		model = "gemini-3-flash-preview"
+	}
+	return &GeminiRewriter{
+		apiKey:  apiKey,
+		model:   model,
+		baseURL: "https://generativelanguage.googleapis.com/v1beta/models/",
+		httpClient: &http.Client{
+			Timeout: 60 * time.Second,
+		},
+	}
+}
+
+// CorrectLLM просит модель исправить только опровергнутые места, опираясь
+// на те же доказательства, что и шаблонный режим, и добавляет сноски
+func (g *GeminiRewriter) CorrectLLM(analysis *AnalysisResult) (*CorrectedResponse, error) {
+	edits, footnotes := planCorrections(analysis)
+	if len(edits) == 0 {
+		return &CorrectedResponse{Mode: CorrectionLLM, Text: analysis.Response}, nil
+	}
+
+	var prompt strings.Builder
+	prompt.WriteString("Исправь ответ ИИ. Меняй только перечисленные ошибочные фрагменты, ")
+	prompt.WriteString("опираясь строго на приведённые доказательства, и сохрани остальной текст дословно. ")
+	prompt.WriteString("После каждого исправления поставь номер сноски в квадратных скобках, если он указан. ")
+	prompt.WriteString("Верни только исправленный текст без пояснений.\n\nОтвет:\n")
+	prompt.WriteString(analysis.Response)
+	prompt.WriteString("\n\nОшибки:\n")
+	for _, e := range edits {
+		fmt.Fprintf(&prompt, "- Фрагмент: «%s»\n  Доказательство: «%s»\n", e.Original, e.Evidence)
+		if e.Citation > 0 {
+			fmt.Fprintf(&prompt, "  Сноска: [%d]\n", e.Citation)
+		}
+	}
+
+	text, err := g.generate(prompt.String())
+	if err != nil {
+		return nil, err
+	}
+
+	return &CorrectedResponse{
+		Mode:      CorrectionLLM,
+		Text:      appendFootnotes(text, footnotes),
+		Edits:     edits,
+		Footnotes: footnotes,
+	}, nil
+}
+
+func (g *GeminiRewriter) generate(prompt string) (string, error) {
+	type part struct {
+		Text string `json:"text"`
+	}
+	type content struct {
+		Parts []part `json:"parts"`
+	}
+	jsonData, err := json.Marshal(map[string][]content{
+		"contents": {{Parts: []part{{Text: prompt}}}},
+	})
+	if err != nil {
+		return "", fmt.Errorf("ошибка сериализации: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", g.baseURL+g.model+":generateContent", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-goog-api-key", g.apiKey)
+
+	resp, err := g.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("ошибка запроса: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("ошибка Gemini (статус %d): %s", resp.StatusCode, string(body))
+	}
+
+	var result struct {
+		Candidates []struct {
+			Content content `json:"content"`
+		} `json:"candidates"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return "", fmt.Errorf("ошибка парсинга ответа: %w", err)
+	}
+
+	var text strings.Builder
+	if len(result.Candidates) > 0 {
+		for _, p := range result.Candidates[0].Content.Parts {
+			text.WriteString(p.Text)
+		}
+	}
+	if strings.TrimSpace(text.String()) == "" {
+		return "", fmt.Errorf("Gemini вернул пустой ответ")
+	}
+	return strings.TrimSpace(text.String()), nil
+}
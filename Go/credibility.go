@@ -0,0 +1,187 @@
+// Go/credibility.go
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+)
+
+const sourcesFile = "data/sources.json"
+
+// SourcePolicy - веса доверия к доменам и списки разрешённых/запрещённых источников.
+// Домен сопоставляется по суффиксу: вес "wikipedia.org" действует и для "ru.wikipedia.org",
+// а вес "gov" - для всех государственных сайтов США.
+type SourcePolicy struct {
+	DefaultWeight float64            `json:"default_weight"`
+	Weights       map[string]float64 `json:"weights"`
+	Allow         []string           `json:"allow,omitempty"`
+	Deny          []string           `json:"deny,omitempty"`
+}
+
+// LoadSourcePolicy читает политику источников; без файла все домены равноправны
+func LoadSourcePolicy(path string) (*SourcePolicy, error) {
+	policy := &SourcePolicy{DefaultWeight: 0.5}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return policy, nil
+	}
+	if err != nil {
+		return policy, fmt.Errorf("не удалось прочитать %s: %w", path, err)
+	}
+
+	if err := json.Unmarshal(data, policy); err != nil {
+		return &SourcePolicy{DefaultWeight: 0.5}, fmt.Errorf("ошибка парсинга %s: %w", path, err)
+	}
+	return policy, nil
+}
+
+// domainOf возвращает хост ссылки без www.
+func domainOf(rawURL string) string {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || u.Hostname() == "" {
+		return ""
+	}
+	return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+}
+
+// lookupSuffix ищет самое точное совпадение домена: a.b.org -> b.org -> org
+func lookupSuffix(domain string, match func(string) bool) bool {
+	for d := domain; d != ""; {
+		if match(d) {
+			return true
+		}
+		_, rest, ok := strings.Cut(d, ".")
+		if !ok {
+			break
+		}
+		d = rest
+	}
+	return false
+}
+
+func domainListed(domain string, list []string) bool {
+	return lookupSuffix(domain, func(d string) bool {
+		for _, entry := range list {
+			if strings.EqualFold(entry, d) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// Credibility - вес доверия к домену от 0 до 1
+func (sp *SourcePolicy) Credibility(domain string) float64 {
+	weight := sp.DefaultWeight
+	lookupSuffix(domain, func(d string) bool {
+		if w, ok := sp.Weights[d]; ok {
+			weight = w
+			return true
+		}
+		return false
+	})
+	return weight
+}
+
+// Allowed - проходит ли домен через allow/deny списки
+func (sp *SourcePolicy) Allowed(domain string) bool {
+	if domainListed(domain, sp.Deny) {
+		return false
+	}
+	if len(sp.Allow) > 0 && !domainListed(domain, sp.Allow) {
+		return false
+	}
+	return true
+}
+
+// Apply отбрасывает запрещённые источники, проставляет доверие к оставшимся
+// и перевзвешивает factuality. Чем надёжнее источники, тем сильнее итог
+// смещается к их взвешенному консенсусу:
+//
+//	factuality' = c̄·ws + (1 − c̄)·factuality
+//
+// где ws - доля подтверждений с весами доверия, c̄ - среднее доверие.
+func (sp *SourcePolicy) Apply(results []FactCheckResult) {
+	for i := range results {
+		r := &results[i]
+
+		if len(r.References) == 0 {
+			if domain := domainOf(r.ReviewURL); domain != "" {
+				r.SourceDomain = domain
+				r.SourceCredibility = sp.Credibility(domain)
+			}
+			continue
+		}
+
+		kept := r.References[:0]
+		for _, ref := range r.References {
+			ref.Domain = domainOf(ref.URL)
+			if !sp.Allowed(ref.Domain) {
+				continue
+			}
+			ref.Credibility = sp.Credibility(ref.Domain)
+			kept = append(kept, ref)
+		}
+		r.References = kept
+		r.SupportingCount, r.RefutingCount, r.SupportRatio = countSupport(kept)
+
+		if len(kept) == 0 {
+			r.ReviewURL, r.KeyQuote = "", ""
+			continue
+		}
+
+		var total, supportive float64
+		for _, ref := range kept {
+			total += ref.Credibility
+			if ref.IsSupportive {
+				supportive += ref.Credibility
+			}
+		}
+		if total > 0 {
+			weightedSupport := supportive / total
+			meanCredibility := total / float64(len(kept))
+
+			r.RawFactuality = r.Factuality
+			r.Factuality = meanCredibility*weightedSupport + (1-meanCredibility)*r.Factuality
+			if r.Confidence == r.RawFactuality {
+				r.Confidence = r.Factuality
+			}
+		}
+
+		best := chooseSource(kept, r.Result)
+		r.ReviewURL = best.URL
+		r.KeyQuote = best.KeyQuote
+		r.SourceDomain = best.Domain
+		r.SourceCredibility = best.Credibility
+	}
+}
+
+// chooseSource выбирает самый надёжный источник, согласный с вердиктом
+func chooseSource(refs []Reference, verdict bool) Reference {
+	best := -1
+	for i, ref := range refs {
+		if ref.IsSupportive != verdict {
+			continue
+		}
+		if best < 0 || ref.Credibility > refs[best].Credibility {
+			best = i
+		}
+	}
+	if best >= 0 {
+		return refs[best]
+	}
+
+	best = 0
+	for i, ref := range refs {
+		if ref.Credibility > refs[best].Credibility {
+			best = i
+		}
+	}
+	return refs[best]
+}
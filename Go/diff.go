@@ -0,0 +1,185 @@
+// Go/diff.go
+
+package main
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Тип операции в diff
+const (
+	diffEqual  = '='
+	diffDelete = '-'
+	diffInsert = '+'
+)
+
+// diffOp - кусок текста, общий для обеих версий, удалённый или добавленный
+type diffOp struct {
+	Kind byte
+	Text string
+}
+
+// tokenizeWords режет текст на слова, знаки препинания и пробелы так,
+// что склейка токенов даёт исходный текст
+func tokenizeWords(text string) []string {
+	var tokens []string
+	var current strings.Builder
+	kind := 0 // 1 - буквы/цифры, 2 - пробелы
+
+	flush := func() {
+		if current.Len() > 0 {
+			tokens = append(tokens, current.String())
+			current.Reset()
+		}
+	}
+
+	for _, r := range text {
+		var k int
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			k = 1
+		case unicode.IsSpace(r):
+			k = 2
+		default:
+			// Пунктуация - всегда отдельный токен
+			flush()
+			tokens = append(tokens, string(r))
+			kind = 0
+			continue
+		}
+		if k != kind {
+			flush()
+			kind = k
+		}
+		current.WriteRune(r)
+	}
+	flush()
+
+	return tokens
+}
+
+// Пределы поиска кратчайшего diff: после стольких правок или шагов по
+// совпадающим токенам изменённая часть показывается как замена целиком
+const (
+	maxDiffEdits = 1000
+	maxDiffSteps = 10_000_000
+)
+
+// diffTokens строит кратчайший diff токенов. Общие начало и конец
+// отрезаются сразу: исправление обычно меняет несколько мест в длинном
+// ответе. Соседние операции одного типа склеиваются.
+func diffTokens(a, b []string) []diffOp {
+	var ops []diffOp
+	push := func(kind byte, text string) {
+		if text == "" {
+			return
+		}
+		if len(ops) > 0 && ops[len(ops)-1].Kind == kind {
+			ops[len(ops)-1].Text += text
+			return
+		}
+		ops = append(ops, diffOp{Kind: kind, Text: text})
+	}
+
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	push(diffEqual, strings.Join(a[:prefix], ""))
+	changedA, changedB := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	if script, ok := myersDiff(changedA, changedB); ok {
+		for _, op := range script {
+			push(op.Kind, op.Text)
+		}
+	} else {
+		push(diffDelete, strings.Join(changedA, ""))
+		push(diffInsert, strings.Join(changedB, ""))
+	}
+	push(diffEqual, strings.Join(a[len(a)-suffix:], ""))
+
+	return ops
+}
+
+// myersDiff - кратчайший diff по алгоритму Майерса за O((N+M)·D);
+// false - если упёрлись в maxDiffEdits или maxDiffSteps
+func myersDiff(a, b []string) ([]diffOp, bool) {
+	n, m := len(a), len(b)
+	steps := 0
+	maxD := min(n+m, maxDiffEdits)
+	offset := maxD + 1
+	v := make([]int, 2*maxD+3) // v[offset+k] - дальний x на диагонали k
+	// trace[d] - диагонали -d..d перед шагом d, для обратного прохода
+	var trace [][]int
+
+	for d := 0; d <= maxD; d++ {
+		trace = append(trace, slices.Clone(v[offset-d:offset+d+1]))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			start := x
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			if steps += x - start + 1; steps > maxDiffSteps {
+				return nil, false
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				return myersBacktrack(a, b, trace), true
+			}
+		}
+	}
+	return nil, false
+}
+
+// myersBacktrack восстанавливает операции от конца к началу по снимкам trace;
+// совпадающие участки склеиваются сразу
+func myersBacktrack(a, b []string, trace [][]int) []diffOp {
+	var ops []diffOp
+	x, y := len(a), len(b)
+	for d := len(trace) - 1; d > 0; d-- {
+		prev := trace[d]
+		k := x - y
+		prevK := k - 1
+		if k == -d || (k != d && prev[k-1+d] < prev[k+1+d]) {
+			prevK = k + 1
+		}
+		prevX := prev[prevK+d]
+		prevY := prevX - prevK
+		if snake := min(x-prevX, y-prevY); snake > 0 {
+			ops = append(ops, diffOp{Kind: diffEqual, Text: strings.Join(a[x-snake:x], "")})
+			x -= snake
+			y -= snake
+		}
+		if x == prevX {
+			y--
+			ops = append(ops, diffOp{Kind: diffInsert, Text: b[y]})
+		} else {
+			x--
+			ops = append(ops, diffOp{Kind: diffDelete, Text: a[x]})
+		}
+	}
+	if x > 0 {
+		ops = append(ops, diffOp{Kind: diffEqual, Text: strings.Join(a[:x], "")})
+	}
+	slices.Reverse(ops)
+	return ops
+}
+
+// diffText - пословный diff двух текстов
+func diffText(original, revised string) []diffOp {
+	return diffTokens(tokenizeWords(original), tokenizeWords(revised))
+}
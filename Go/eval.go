@@ -0,0 +1,412 @@
+// Go/eval.go
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	evalCacheFile = "data/eval/jina_cache.json"
+
+	// Проверщики для -verifiers: база мифов локальная, Jina - через кэш
+	evalVerifierMyths = "myths"
+	evalVerifierJina  = "jina"
+)
+
+// errEvalRunNotFound - в истории нет прогона оценки с таким номером
+var errEvalRunNotFound = errors.New("прогон оценки не найден")
+
+// EvalGoldClaim - утверждение с эталонным вердиктом
+type EvalGoldClaim struct {
+	Claim   string  `json:"claim"`
+	Verdict Verdict `json:"verdict"`
+}
+
+// EvalItem - строка набора данных: либо одно утверждение (Claim, Verdict),
+// либо размеченные утверждения одного ответа (Claims). Оценивается только
+// проверка: утверждения берутся из разметки, текст ответа через извлечение
+// не прогоняется, поэтому поле response в наборе не читается.
+type EvalItem struct {
+	ID      string          `json:"id,omitempty"`
+	Query   string          `json:"query,omitempty"`
+	Claim   string          `json:"claim,omitempty"`
+	Verdict Verdict         `json:"verdict,omitempty"`
+	Claims  []EvalGoldClaim `json:"claims,omitempty"`
+}
+
+// EvalConfig - проверяемая конфигурация: цепочка проверщиков по порядку
+// и файлы порогов, источников, базы мифов и калибровки (пустой - без неё).
+// Offline - не ходить в сеть, утверждения без ответа в кэше считаются
+// ошибкой проверки.
+type EvalConfig struct {
+	Verifiers     []string `json:"verifiers"`
+	Thresholds    string   `json:"thresholds"`
+	Sources       string   `json:"sources"`
+	Myths         string   `json:"myths"`
+	Calibration   string   `json:"calibration,omitempty"`
+	Cache         string   `json:"cache,omitempty"`
+	Offline       bool     `json:"offline,omitempty"`
+	ReferenceDate string   `json:"as_of,omitempty"`
+}
+
+// EvalClaim - эталон и предсказание по одному утверждению
+type EvalClaim struct {
+	Item       string  `json:"item"`
+	Claim      string  `json:"claim"`
+	Gold       Verdict `json:"gold"`
+	Predicted  Verdict `json:"predicted"`
+	Confidence float64 `json:"confidence"`
+	Verifier   string  `json:"verifier,omitempty"`
+	Cached     bool    `json:"cached,omitempty"`
+	Error      string  `json:"error,omitempty"`
+}
+
+// EvalReport - прогон оценки; ID - номер в истории, если прогон сохранён
+type EvalReport struct {
+	ID         int64             `json:"id,omitempty"`
+	Name       string            `json:"name,omitempty"`
+	Dataset    string            `json:"dataset"`
+	CreatedAt  time.Time         `json:"created_at"`
+	DurationMs int64             `json:"duration_ms"`
+	Config     EvalConfig        `json:"config"`
+	Thresholds VerdictThresholds `json:"thresholds"`
+	Claims     []EvalClaim       `json:"claims"`
+	Metrics    EvalMetrics       `json:"metrics"`
+}
+
+// LoadEvalDataset читает размеченный набор из JSONL; эталонный вердикт -
+// supported, refuted, disputed или unverifiable
+func LoadEvalDataset(path string) ([]EvalItem, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось прочитать файл: %w", err)
+	}
+
+	var items []EvalItem
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), maxInputSize)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+		var item EvalItem
+		if err := json.Unmarshal(text, &item); err != nil {
+			return nil, fmt.Errorf("строка %d: ошибка парсинга JSONL: %w", line, err)
+		}
+		if item.Claim != "" {
+			item.Claims = append([]EvalGoldClaim{{Claim: item.Claim, Verdict: item.Verdict}}, item.Claims...)
+		}
+		if len(item.Claims) == 0 {
+			return nil, fmt.Errorf("строка %d: нет ни claim, ни claims", line)
+		}
+		for _, c := range item.Claims {
+			switch c.Verdict {
+			case VerdictSupported, VerdictRefuted, VerdictDisputed, VerdictUnverifiable:
+			default:
+				return nil, fmt.Errorf("строка %d: неизвестный эталонный вердикт %q", line, c.Verdict)
+			}
+		}
+		if item.ID == "" {
+			item.ID = fmt.Sprintf("%d", line)
+		}
+		items = append(items, item)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка чтения JSONL: %w", err)
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("в наборе нет размеченных утверждений")
+	}
+	return items, nil
+}
+
+// parseEvalVerifiers разбирает "myths,jina" в цепочку проверщиков
+func parseEvalVerifiers(list string) ([]string, error) {
+	var verifiers []string
+	for _, v := range strings.Split(list, ",") {
+		v = strings.TrimSpace(v)
+		switch v {
+		case "":
+			continue
+		case evalVerifierMyths, evalVerifierJina:
+			verifiers = append(verifiers, v)
+		default:
+			return nil, fmt.Errorf("неизвестный проверщик %q: доступны %s и %s", v, evalVerifierMyths, evalVerifierJina)
+		}
+	}
+	if len(verifiers) == 0 {
+		return nil, fmt.Errorf("не указан ни один проверщик")
+	}
+	return verifiers, nil
+}
+
+// jinaCache - ответы Jina по утверждениям до доводки результата; повторный
+// прогон с другими порогами или источниками не ходит в сеть
+type jinaCache struct {
+	path    string
+	entries map[string]FactCheckResult
+}
+
+func loadJinaCache(path string) (*jinaCache, error) {
+	cache := &jinaCache{path: path, entries: make(map[string]FactCheckResult)}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cache, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("не удалось прочитать кэш %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, &cache.entries); err != nil {
+		return nil, fmt.Errorf("ошибка парсинга кэша %s: %w", path, err)
+	}
+	return cache, nil
+}
+
+// jinaCacheKey - ответ Jina зависит от даты, на которую проверяется утверждение
+func jinaCacheKey(claim, referenceDate string) string {
+	if referenceDate == "" {
+		return claim
+	}
+	return claim + " @" + referenceDate
+}
+
+func (c *jinaCache) get(key string) (FactCheckResult, bool) {
+	r, ok := c.entries[key]
+	// Доводка результата меняет источники на месте - кэш не должен это видеть
+	r.References = slices.Clone(r.References)
+	return r, ok
+}
+
+// put запоминает ответ и сразу пишет кэш на диск: прерванный прогон не теряет запросы
+func (c *jinaCache) put(key string, r FactCheckResult) error {
+	r.References = slices.Clone(r.References)
+	c.entries[key] = r
+
+	data, err := json.MarshalIndent(c.entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("ошибка сериализации кэша: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o755); err != nil {
+		return fmt.Errorf("не удалось создать папку кэша: %w", err)
+	}
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("не удалось сохранить кэш: %w", err)
+	}
+	return os.Rename(tmp, c.path)
+}
+
+// evalRunner проверяет утверждения набора цепочкой проверщиков из конфигурации
+type evalRunner struct {
+	config EvalConfig
+	stages *claimStages
+	cache  *jinaCache
+	jina   *JinaClient
+}
+
+func newEvalRunner(config EvalConfig, jinaKey string) (*evalRunner, error) {
+	thresholds, err := LoadVerdictThresholds(config.Thresholds)
+	if err != nil {
+		return nil, err
+	}
+	sources, err := LoadSourcePolicy(config.Sources)
+	if err != nil {
+		return nil, err
+	}
+	calibration, err := LoadCalibration(config.Calibration)
+	if err != nil {
+		return nil, err
+	}
+	r := &evalRunner{config: config, stages: &claimStages{sources: sources, thresholds: thresholds, calibration: calibration}}
+
+	if slices.Contains(config.Verifiers, evalVerifierMyths) {
+		if r.stages.myths, err = LoadMisconceptionDB(config.Myths); err != nil {
+			return nil, err
+		}
+	}
+	if slices.Contains(config.Verifiers, evalVerifierJina) {
+		if r.cache, err = loadJinaCache(config.Cache); err != nil {
+			return nil, err
+		}
+		if !config.Offline {
+			if jinaKey == "" {
+				return nil, fmt.Errorf("для проверки через Jina нужен JINA_API_KEY; без сети - флаг -offline")
+			}
+			r.jina = NewJinaClient(jinaKey)
+			r.jina.ReferenceDate = config.ReferenceDate
+		}
+	}
+	return r, nil
+}
+
+// verify проверяет утверждение первым подходящим проверщиком и доводит результат
+// так же, как пайплайн; cached - ответ Jina взят из кэша
+func (r *evalRunner) verify(claim string) (result FactCheckResult, cached bool) {
+	started := time.Now()
+	result = FactCheckResult{Claim: claim, Found: true, Verdict: VerdictUnverifiable}
+
+chain:
+	for _, verifier := range r.config.Verifiers {
+		switch verifier {
+		case evalVerifierMyths:
+			if matched, ok := r.stages.myths.CheckClaim(claim); ok {
+				result = matched
+				break chain
+			}
+		case evalVerifierJina:
+			key := jinaCacheKey(claim, r.config.ReferenceDate)
+			if hit, ok := r.cache.get(key); ok {
+				result, cached = hit, true
+				break chain
+			}
+			if r.jina == nil {
+				result = FactCheckResult{Claim: claim, Verifier: "jina", Error: "нет в кэше"}
+				break chain
+			}
+			checked, err := r.jina.CheckClaim(claim)
+			if err != nil {
+				result = FactCheckResult{Claim: claim, Verifier: "jina", Error: err.Error()}
+				break chain
+			}
+			if err := r.cache.put(key, checked); err != nil {
+				fmt.Fprintf(os.Stderr, "  ⚠️  %v\n", err)
+			}
+			result = checked
+			break chain
+		}
+	}
+
+	result.DurationMs = time.Since(started).Milliseconds()
+	results := []FactCheckResult{result}
+	r.stages.finalize(results, claim, r.config.ReferenceDate, nil)
+	return results[0], cached
+}
+
+// RunEval прогоняет набор через конфигурацию и считает метрики;
+// progress вызывается после каждого утверждения (может быть nil)
+func RunEval(dataset string, items []EvalItem, config EvalConfig, jinaKey string, progress func(done, total int)) (*EvalReport, error) {
+	runner, err := newEvalRunner(config, jinaKey)
+	if err != nil {
+		return nil, err
+	}
+
+	total := 0
+	for _, item := range items {
+		total += len(item.Claims)
+	}
+
+	report := &EvalReport{
+		Dataset:    dataset,
+		CreatedAt:  time.Now().UTC(),
+		Config:     config,
+		Thresholds: runner.stages.thresholds,
+	}
+	for _, item := range items {
+		for _, gold := range item.Claims {
+			result, cached := runner.verify(gold.Claim)
+			report.Claims = append(report.Claims, EvalClaim{
+				Item:       item.ID,
+				Claim:      gold.Claim,
+				Gold:       gold.Verdict,
+				Predicted:  result.Verdict,
+				Confidence: truthProbability(result),
+				Verifier:   result.Verifier,
+				Cached:     cached,
+				Error:      result.Error,
+			})
+			if progress != nil {
+				progress(len(report.Claims), total)
+			}
+		}
+	}
+
+	report.Metrics = ComputeEvalMetrics(report.Claims)
+	report.DurationMs = time.Since(report.CreatedAt).Milliseconds()
+	return report, nil
+}
+
+// EvalRunEntry - прогон оценки в списке
+type EvalRunEntry struct {
+	ID        int64
+	CreatedAt time.Time
+	Name      string
+	Dataset   string
+	Claims    int
+	Accuracy  float64
+	MacroF1   float64
+	RefutedF1 float64
+	ECE       float64
+}
+
+// SaveEvalRun сохраняет прогон оценки и записывает номер в report.ID
+func (h *HistoryStore) SaveEvalRun(report *EvalReport) (int64, error) {
+	data, err := json.Marshal(report)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка сериализации: %w", err)
+	}
+	m := report.Metrics
+	res, err := h.db.Exec(`INSERT INTO eval_runs (created_at, name, dataset, claims, accuracy, macro_f1, refuted_f1, ece, report)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		report.CreatedAt.Format(time.RFC3339Nano), report.Name, report.Dataset, m.Claims,
+		m.Accuracy, m.MacroF1, m.Class(VerdictRefuted).F1, m.Calibration.ECE, string(data))
+	if err != nil {
+		return 0, fmt.Errorf("ошибка сохранения прогона: %w", err)
+	}
+	report.ID, err = res.LastInsertId()
+	return report.ID, err
+}
+
+// EvalRuns - последние прогоны оценки, новые первыми
+func (h *HistoryStore) EvalRuns(limit int) ([]EvalRunEntry, error) {
+	rows, err := h.db.Query(`SELECT id, created_at, name, dataset, claims, accuracy, macro_f1, refuted_f1, ece
+		FROM eval_runs ORDER BY id DESC LIMIT ?`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения прогонов: %w", err)
+	}
+	defer rows.Close()
+
+	var runs []EvalRunEntry
+	for rows.Next() {
+		var e EvalRunEntry
+		var createdAt string
+		if err := rows.Scan(&e.ID, &createdAt, &e.Name, &e.Dataset, &e.Claims, &e.Accuracy, &e.MacroF1, &e.RefutedF1, &e.ECE); err != nil {
+			return nil, fmt.Errorf("ошибка чтения прогонов: %w", err)
+		}
+		e.CreatedAt, _ = time.Parse(time.RFC3339Nano, createdAt)
+		runs = append(runs, e)
+	}
+	return runs, rows.Err()
+}
+
+// EvalRun возвращает сохранённый прогон целиком
+func (h *HistoryStore) EvalRun(id int64) (*EvalReport, error) {
+	var data string
+	err := h.db.QueryRow("SELECT report FROM eval_runs WHERE id = ?", id).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("#%d: %w", id, errEvalRunNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения прогона: %w", err)
+	}
+
+	var report EvalReport
+	if err := json.Unmarshal([]byte(data), &report); err != nil {
+		return nil, fmt.Errorf("прогон #%d повреждён: %w", id, err)
+	}
+	report.ID = id
+	return &report, nil
+}
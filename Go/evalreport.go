@@ -0,0 +1,440 @@
+// Go/evalreport.go
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/muesli/termenv"
+)
+
+// calibrationBins - на сколько равных отрезков делится уверенность 0..1
+const calibrationBins = 10
+
+// evalVerdicts - порядок вердиктов в матрице ошибок
+var evalVerdicts = []Verdict{VerdictSupported, VerdictRefuted, VerdictDisputed, VerdictUnverifiable, VerdictError}
+
+// ClassMetrics - точность и полнота по одному вердикту. Support - сколько
+// утверждений с таким эталоном, Predicted - сколько с таким предсказанием.
+type ClassMetrics struct {
+	Verdict   Verdict `json:"verdict"`
+	Precision float64 `json:"precision"`
+	Recall    float64 `json:"recall"`
+	F1        float64 `json:"f1"`
+	Support   int     `json:"support"`
+	Predicted int     `json:"predicted"`
+}
+
+// CalibrationBin - отрезок уверенности [Lower, Upper): средняя уверенность
+// в истинности утверждения и доля действительно верных по эталону
+type CalibrationBin struct {
+	Lower      float64 `json:"lower"`
+	Upper      float64 `json:"upper"`
+	Confidence float64 `json:"confidence"`
+	Observed   float64 `json:"observed"`
+	Count      int     `json:"count"`
+}
+
+// CalibrationCurve - калибровка уверенности проверщика (пустой Verifier - всех).
+// ECE - средний разрыв уверенности и доли верных по отрезкам с весом их размера.
+type CalibrationCurve struct {
+	Verifier string           `json:"verifier,omitempty"`
+	Count    int              `json:"count"`
+	ECE      float64          `json:"ece"`
+	Brier    float64          `json:"brier"`
+	Bins     []CalibrationBin `json:"bins"`
+}
+
+// EvalMetrics - качество проверки на наборе. Confusion[эталон][предсказание]
+// в порядке evalVerdicts; Responses - поиск ответов хотя бы с одной
+// галлюцинацией, если в наборе есть ответы из нескольких утверждений.
+type EvalMetrics struct {
+	Claims      int                `json:"claims"`
+	Errors      int                `json:"errors"`
+	Accuracy    float64            `json:"accuracy"`
+	MacroF1     float64            `json:"macro_f1"`
+	Classes     []ClassMetrics     `json:"classes"`
+	Confusion   [][]int            `json:"confusion"`
+	Responses   *ClassMetrics      `json:"responses,omitempty"`
+	Calibration CalibrationCurve   `json:"calibration"`
+	ByVerifier  []CalibrationCurve `json:"by_verifier,omitempty"`
+}
+
+// Class - метрики по вердикту; нулевые, если такого эталона в наборе нет
+func (m EvalMetrics) Class(v Verdict) ClassMetrics {
+	for _, c := range m.Classes {
+		if c.Verdict == v {
+			return c
+		}
+	}
+	return ClassMetrics{Verdict: v}
+}
+
+// ComputeEvalMetrics считает метрики по эталонам и предсказаниям
+func ComputeEvalMetrics(claims []EvalClaim) EvalMetrics {
+	index := make(map[Verdict]int, len(evalVerdicts))
+	for i, v := range evalVerdicts {
+		index[v] = i
+	}
+	m := EvalMetrics{Claims: len(claims), Confusion: make([][]int, len(evalVerdicts))}
+	for i := range m.Confusion {
+		m.Confusion[i] = make([]int, len(evalVerdicts))
+	}
+
+	correct := 0
+	for _, c := range claims {
+		m.Confusion[index[c.Gold]][index[c.Predicted]]++
+		if c.Gold == c.Predicted {
+			correct++
+		}
+		if c.Predicted == VerdictError {
+			m.Errors++
+		}
+	}
+	if len(claims) > 0 {
+		m.Accuracy = float64(correct) / float64(len(claims))
+	}
+
+	// Классы - только те, что встречаются в эталоне: macro-F1 по ним
+	for i, v := range evalVerdicts {
+		support, predicted := 0, 0
+		for j := range evalVerdicts {
+			support += m.Confusion[i][j]
+			predicted += m.Confusion[j][i]
+		}
+		if support == 0 {
+			continue
+		}
+		m.Classes = append(m.Classes, classMetrics(v, m.Confusion[i][i], support, predicted))
+		m.MacroF1 += m.Classes[len(m.Classes)-1].F1
+	}
+	if len(m.Classes) > 0 {
+		m.MacroF1 /= float64(len(m.Classes))
+	}
+
+	m.Responses = responseMetrics(claims)
+	m.Calibration = calibrationCurve("", claims)
+	var verifiers []string
+	for _, c := range claims {
+		if c.Verifier != "" && !slices.Contains(verifiers, c.Verifier) {
+			verifiers = append(verifiers, c.Verifier)
+		}
+	}
+	for _, v := range verifiers {
+		if curve := calibrationCurve(v, claims); curve.Count > 0 {
+			m.ByVerifier = append(m.ByVerifier, curve)
+		}
+	}
+	return m
+}
+
+func classMetrics(v Verdict, tp, support, predicted int) ClassMetrics {
+	c := ClassMetrics{Verdict: v, Support: support, Predicted: predicted}
+	if predicted > 0 {
+		c.Precision = float64(tp) / float64(predicted)
+	}
+	if support > 0 {
+		c.Recall = float64(tp) / float64(support)
+	}
+	if c.Precision+c.Recall > 0 {
+		c.F1 = 2 * c.Precision * c.Recall / (c.Precision + c.Recall)
+	}
+	return c
+}
+
+// responseMetrics - ответ считается галлюцинирующим, если хоть одно его
+// утверждение опровергнуто; nil, если в наборе только отдельные утверждения
+func responseMetrics(claims []EvalClaim) *ClassMetrics {
+	type outcome struct{ gold, predicted bool }
+	var order []string
+	items := make(map[string]*outcome)
+	for _, c := range claims {
+		o, ok := items[c.Item]
+		if !ok {
+			o = &outcome{}
+			items[c.Item] = o
+			order = append(order, c.Item)
+		}
+		o.gold = o.gold || c.Gold == VerdictRefuted
+		o.predicted = o.predicted || c.Predicted == VerdictRefuted
+	}
+	if len(order) == len(claims) {
+		return nil
+	}
+
+	tp, support, predicted := 0, 0, 0
+	for _, id := range order {
+		o := items[id]
+		if o.gold {
+			support++
+		}
+		if o.predicted {
+			predicted++
+		}
+		if o.gold && o.predicted {
+			tp++
+		}
+	}
+	m := classMetrics(VerdictRefuted, tp, support, predicted)
+	return &m
+}
+
+// calibrationCurve - калибровка по утверждениям с эталоном supported или
+// refuted, для которых проверщик выдал оценку
+func calibrationCurve(verifier string, claims []EvalClaim) CalibrationCurve {
+	curve := CalibrationCurve{Verifier: verifier, Bins: make([]CalibrationBin, calibrationBins)}
+	for i := range curve.Bins {
+		curve.Bins[i].Lower = float64(i) / calibrationBins
+		curve.Bins[i].Upper = float64(i+1) / calibrationBins
+	}
+
+	for _, c := range claims {
+		if c.Verifier == "" || c.Predicted == VerdictError || (verifier != "" && c.Verifier != verifier) {
+			continue
+		}
+		if c.Gold != VerdictSupported && c.Gold != VerdictRefuted {
+			continue
+		}
+		truth := 0.0
+		if c.Gold == VerdictSupported {
+			truth = 1
+		}
+		p := math.Min(math.Max(c.Confidence, 0), 1)
+		bin := &curve.Bins[min(int(p*calibrationBins), calibrationBins-1)]
+		bin.Confidence += p
+		bin.Observed += truth
+		bin.Count++
+		curve.Brier += (p - truth) * (p - truth)
+		curve.Count++
+	}
+	if curve.Count == 0 {
+		return curve
+	}
+
+	for i := range curve.Bins {
+		b := &curve.Bins[i]
+		if b.Count == 0 {
+			continue
+		}
+		b.Confidence /= float64(b.Count)
+		b.Observed /= float64(b.Count)
+		curve.ECE += float64(b.Count) / float64(curve.Count) * math.Abs(b.Confidence-b.Observed)
+	}
+	curve.Brier /= float64(curve.Count)
+	return curve
+}
+
+// printEvalReport - метрики прогона, матрица ошибок и кривые калибровки
+func printEvalReport(report *EvalReport) {
+	p := termenv.ColorProfile()
+	colorHeader := p.Color("#00BFFF")
+	colorDim := p.Color("#8B949E")
+	colorText := p.Color("#E6EDF3")
+	colorErr := p.Color("#FF6B6B")
+	m := report.Metrics
+
+	fmt.Println()
+	fmt.Println(termenv.String("  ══════════════════════════════════════════").Foreground(colorHeader))
+	fmt.Println(termenv.String("              ОЦЕНКА ПРОВЕРКИ               ").Foreground(colorText))
+	fmt.Println(termenv.String("  ══════════════════════════════════════════").Foreground(colorHeader))
+
+	title := fmt.Sprintf("  Набор: %s, утверждений: %d", report.Dataset, m.Claims)
+	if report.ID > 0 {
+		title = fmt.Sprintf("  Прогон #%d. ", report.ID) + strings.TrimSpace(title)
+	}
+	if report.Name != "" {
+		title += fmt.Sprintf(" (%s)", report.Name)
+	}
+	fmt.Println(termenv.String(title).Foreground(colorText))
+	fmt.Println(termenv.String("  " + evalConfigText(report)).Foreground(colorDim))
+
+	fmt.Printf("\n  Доля верных вердиктов: %.1f%%   macro-F1: %.3f", m.Accuracy*100, m.MacroF1)
+	if m.Errors > 0 {
+		fmt.Print(termenv.String(fmt.Sprintf("   ошибок проверки: %d", m.Errors)).Foreground(colorErr))
+	}
+	fmt.Println()
+
+	fmt.Println(termenv.String("\n  🎯 По вердиктам").Foreground(colorHeader).Bold())
+	fmt.Println(termenv.String(fmt.Sprintf("     %-10s %9s %9s %9s %7s", "эталон", "precision", "recall", "F1", "n")).Foreground(colorDim))
+	for _, c := range m.Classes {
+		fmt.Printf("     %-10s %9.3f %9.3f %9.3f %7d\n", verdictShort(c.Verdict), c.Precision, c.Recall, c.F1, c.Support)
+	}
+	if r := m.Responses; r != nil {
+		fmt.Printf("     %-10s %9.3f %9.3f %9.3f %7d\n", "ответы*", r.Precision, r.Recall, r.F1, r.Support)
+		fmt.Println(termenv.String("     * ответы, где опровергнуто хотя бы одно утверждение").Foreground(colorDim))
+	}
+
+	fmt.Println(termenv.String("\n  🔢 Матрица ошибок (строки - эталон, столбцы - предсказание)").Foreground(colorHeader).Bold())
+	header := fmt.Sprintf("     %-10s", "")
+	for _, v := range evalVerdicts {
+		header += fmt.Sprintf(" %7s", verdictShort(v))
+	}
+	fmt.Println(termenv.String(header).Foreground(colorDim))
+	for i, v := range evalVerdicts {
+		if m.Class(v).Support == 0 {
+			continue
+		}
+		fmt.Printf("     %-10s", verdictShort(v))
+		for j, n := range m.Confusion[i] {
+			cell := termenv.String(fmt.Sprintf(" %7d", n))
+			switch {
+			case i == j:
+				cell = cell.Foreground(p.Color("#3FB950"))
+			case n > 0:
+				cell = cell.Foreground(colorErr)
+			default:
+				cell = cell.Foreground(colorDim)
+			}
+			fmt.Print(cell)
+		}
+		fmt.Println()
+	}
+
+	curves := append([]CalibrationCurve{m.Calibration}, m.ByVerifier...)
+	for _, curve := range curves {
+		if curve.Count == 0 {
+			continue
+		}
+		name := "все проверщики"
+		if curve.Verifier != "" {
+			name = curve.Verifier
+		}
+		fmt.Println(termenv.String(fmt.Sprintf("\n  📐 Калибровка: %s (n=%d, ECE %.3f, Brier %.3f)", name, curve.Count, curve.ECE, curve.Brier)).Foreground(colorHeader).Bold())
+		printCalibrationCurve(curve)
+	}
+}
+
+// printCalibrationCurve - по отрезку уверенности: полоса - доля верных
+// утверждений, │ - где она должна быть при идеальной калибровке
+func printCalibrationCurve(curve CalibrationCurve) {
+	p := termenv.ColorProfile()
+	colorDim := p.Color("#8B949E")
+	const width = 20
+
+	for _, b := range curve.Bins {
+		if b.Count == 0 {
+			continue
+		}
+		track := []rune(strings.Repeat("█", int(math.Round(b.Observed*width))) + strings.Repeat("░", width))[:width+1]
+		track[min(int(math.Round(b.Confidence*width)), width)] = '│'
+		gap := math.Abs(b.Confidence - b.Observed)
+		fmt.Printf("     %.1f–%.1f %s", b.Lower, b.Upper, termenv.String(string(track)).Foreground(rateColor(p, gap)))
+		fmt.Println(termenv.String(fmt.Sprintf("  уверенность %3.0f%%, верно %3.0f%%, n=%d", b.Confidence*100, b.Observed*100, b.Count)).Foreground(colorDim))
+	}
+}
+
+// evalConfigText - конфигурация прогона одной строкой
+func evalConfigText(report *EvalReport) string {
+	c := report.Config
+	text := fmt.Sprintf("Проверщики: %s; пороги %.2f/%.2f, источников не меньше %d",
+		strings.Join(c.Verifiers, " → "), report.Thresholds.Supported, report.Thresholds.Refuted, report.Thresholds.MinReferences)
+	if c.Calibration != "" {
+		text += "; калибровка " + c.Calibration
+	}
+	if c.Offline {
+		text += "; офлайн"
+	}
+	if c.ReferenceDate != "" {
+		text += "; на дату " + c.ReferenceDate
+	}
+	return text
+}
+
+// printEvalRuns - список сохранённых прогонов
+func printEvalRuns(runs []EvalRunEntry) {
+	p := termenv.ColorProfile()
+	colorDim := p.Color("#8B949E")
+
+	if len(runs) == 0 {
+		fmt.Println(termenv.String("  Сохранённых прогонов нет").Foreground(colorDim))
+		return
+	}
+	fmt.Println(termenv.String(fmt.Sprintf("  %5s  %-16s  %-20s %6s %8s %8s %8s %7s", "#", "когда", "набор", "n", "верно", "macroF1", "F1 ложь", "ECE")).Foreground(colorDim))
+	for _, r := range runs {
+		dataset := r.Dataset
+		if r.Name != "" {
+			dataset = r.Name
+		}
+		fmt.Printf("  %5d  %-16s  %-20s %6d %7.1f%% %8.3f %8.3f %7.3f\n", r.ID, r.CreatedAt.Local().Format("2006-01-02 15:04"),
+			truncateText(dataset, 20), r.Claims, r.Accuracy*100, r.MacroF1, r.RefutedF1, r.ECE)
+	}
+}
+
+// printEvalDiff сравнивает два прогона: метрики и утверждения, где изменился вердикт
+func printEvalDiff(base, next *EvalReport) {
+	p := termenv.ColorProfile()
+	colorHeader := p.Color("#00BFFF")
+	colorDim := p.Color("#8B949E")
+	colorOk := p.Color("#3FB950")
+	colorErr := p.Color("#FF6B6B")
+
+	fmt.Println(termenv.String(fmt.Sprintf("\n  ⚖️  Прогон #%d → #%d", base.ID, next.ID)).Foreground(colorHeader).Bold())
+	fmt.Println(termenv.String(fmt.Sprintf("     #%d: %s", base.ID, evalConfigText(base))).Foreground(colorDim))
+	fmt.Println(termenv.String(fmt.Sprintf("     #%d: %s", next.ID, evalConfigText(next))).Foreground(colorDim))
+	if base.Dataset != next.Dataset {
+		fmt.Println(termenv.String(fmt.Sprintf("     ⚠️  Разные наборы: %s и %s", base.Dataset, next.Dataset)).Foreground(p.Color("#D29922")))
+	}
+
+	// higher - метрика лучше, когда больше; для ECE и Brier наоборот
+	row := func(name string, a, b float64, higher bool) {
+		delta := b - a
+		color := colorDim
+		if math.Abs(delta) >= 0.0005 {
+			if (delta > 0) == higher {
+				color = colorOk
+			} else {
+				color = colorErr
+			}
+		}
+		fmt.Printf("     %-18s %8.3f %8.3f ", name, a, b)
+		fmt.Println(termenv.String(fmt.Sprintf("%+8.3f", delta)).Foreground(color))
+	}
+
+	fmt.Println(termenv.String(fmt.Sprintf("\n     %-18s %8s %8s %8s", "", fmt.Sprintf("#%d", base.ID), fmt.Sprintf("#%d", next.ID), "Δ")).Foreground(colorDim))
+	a, b := base.Metrics, next.Metrics
+	row("доля верных", a.Accuracy, b.Accuracy, true)
+	row("macro-F1", a.MacroF1, b.MacroF1, true)
+	for _, v := range evalVerdicts {
+		if a.Class(v).Support > 0 || b.Class(v).Support > 0 {
+			row("F1 "+verdictShort(v), a.Class(v).F1, b.Class(v).F1, true)
+		}
+	}
+	row("ECE", a.Calibration.ECE, b.Calibration.ECE, false)
+	row("Brier", a.Calibration.Brier, b.Calibration.Brier, false)
+
+	before := make(map[string]EvalClaim, len(base.Claims))
+	for _, c := range base.Claims {
+		before[c.Item+"\x00"+c.Claim] = c
+	}
+	var fixed, broken []EvalClaim
+	for _, c := range next.Claims {
+		old, ok := before[c.Item+"\x00"+c.Claim]
+		if !ok || old.Predicted == c.Predicted {
+			continue
+		}
+		switch {
+		case c.Predicted == c.Gold:
+			fixed = append(fixed, c)
+		case old.Predicted == old.Gold:
+			broken = append(broken, c)
+		}
+	}
+	printChanged := func(title string, claims []EvalClaim, color termenv.Color) {
+		if len(claims) == 0 {
+			return
+		}
+		fmt.Println(termenv.String(fmt.Sprintf("\n  %s: %d", title, len(claims))).Foreground(color).Bold())
+		for i, c := range claims {
+			if i == 10 {
+				fmt.Println(termenv.String(fmt.Sprintf("     … и ещё %d", len(claims)-10)).Foreground(colorDim))
+				break
+			}
+			fmt.Printf("     %s (эталон: %s, стало: %s)\n", truncateText(c.Claim, 60), verdictShort(c.Gold), verdictShort(c.Predicted))
+		}
+	}
+	printChanged("✅ Стало верно", fixed, colorOk)
+	printChanged("❌ Стало неверно", broken, colorErr)
+}
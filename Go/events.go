@@ -0,0 +1,27 @@
+// Go/events.go
+
+package main
+
+// Типы событий проверки: по ним REPL рисует прогресс, а сервер шлёт SSE
+const (
+	EventNote      = "note"      // сообщение о ходе проверки (Level: info, ok, warn)
+	EventExtracted = "extracted" // утверждения извлечены из ответа
+	EventClaim     = "claim"     // утверждение проверено, Result - окончательный результат
+	EventSummary   = "summary"   // проверка завершена, Analysis - полный результат
+	EventError     = "error"     // проверка прервана
+)
+
+// AnalysisEvent - событие хода проверки. Index - номер утверждения (с 1)
+// в итоговом FactCheckResults, Total - сколько всего утверждений (0 при
+// потоковой проверке, пока ответ не дописан).
+type AnalysisEvent struct {
+	Type     string           `json:"type"`
+	ID       string           `json:"id,omitempty"`
+	Level    string           `json:"level,omitempty"`
+	Message  string           `json:"message,omitempty"`
+	Claims   []string         `json:"claims,omitempty"`
+	Index    int              `json:"index,omitempty"`
+	Total    int              `json:"total,omitempty"`
+	Result   *FactCheckResult `json:"result,omitempty"`
+	Analysis *AnalysisResult  `json:"analysis,omitempty"`
+}
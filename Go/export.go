@@ -0,0 +1,378 @@
+// Go/export.go
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ExportAnalysis сохраняет результат анализа; формат выбирается по расширению:
+// .json - AnalysisResult как есть, .md - отчёт в Markdown,
+// .html - ответ с подсветкой фрагментов и сносками на источники
+func ExportAnalysis(analysis *AnalysisResult, path string) error {
+	var data []byte
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		var err error
+		data, err = json.MarshalIndent(analysis, "", "  ")
+		if err != nil {
+			return fmt.Errorf("ошибка сериализации: %w", err)
+		}
+	case ".md":
+		data = []byte(renderMarkdown(analysis))
+	case ".html", ".htm":
+		var buf bytes.Buffer
+		if err := renderHTML(&buf, analysis); err != nil {
+			return fmt.Errorf("ошибка HTML шаблона: %w", err)
+		}
+		data = buf.Bytes()
+	default:
+		return fmt.Errorf("неизвестный формат %q: поддерживаются .json, .md и .html", filepath.Ext(path))
+	}
+
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("не удалось создать папку: %w", err)
+		}
+	}
+
+	return os.WriteFile(path, data, 0o644)
+}
+
+func renderMarkdown(analysis *AnalysisResult) string {
+	var b strings.Builder
+	s := analysis.Summary
+
+	b.WriteString("# Результаты проверки\n\n")
+	if analysis.Query != "" {
+		fmt.Fprintf(&b, "**Вопрос:** %s\n\n", analysis.Query)
+	}
+	fmt.Fprintf(&b, "**Ответ:**\n\n> %s\n\n", strings.ReplaceAll(analysis.Response, "\n", "\n> "))
+
+	if c := analysis.Correction; c != nil {
+		fmt.Fprintf(&b, "**Исправленный ответ** (%s):\n\n> %s\n\n", c.Mode, strings.ReplaceAll(strings.TrimRight(c.Text, "\n"), "\n", "\n> "))
+	}
+
+	if rel := analysis.Relevance; rel != nil {
+		b.WriteString("## Релевантность\n\n")
+		if rel.Addressed {
+			b.WriteString("- Ответ по существу вопроса\n")
+		} else {
+			b.WriteString("- **Ответ не отвечает на вопрос**\n")
+		}
+		fmt.Fprintf(&b, "- Покрытие вопроса: %.0f%%\n", rel.Score*100)
+		if rel.ExpectedType != "" {
+			fmt.Fprintf(&b, "- Ожидаемый тип ответа: %s, найден: %t\n", rel.ExpectedType, rel.AnswerFound)
+		}
+		if len(rel.Unrequested) > 0 {
+			fmt.Fprintf(&b, "- Утверждения не по вопросу: %v\n", rel.Unrequested)
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("## Утверждения\n\n")
+	b.WriteString("| # | Утверждение | Вердикт | Достоверность | Источник |\n")
+	b.WriteString("|---|---|---|---|---|\n")
+	for i, r := range analysis.FactCheckResults {
+		source := r.ReviewURL
+		if r.SourceDomain != "" {
+			source = fmt.Sprintf("[%s](%s) (%.0f%%)", r.SourceDomain, r.ReviewURL, r.SourceCredibility*100)
+		}
+		verdict := verdictLabel(r.Verdict)
+		if r.PossiblyOutdated {
+			verdict += ", возможно устарело"
+		}
+		for _, m := range r.Mismatches {
+			verdict += "; " + mismatchText(m)
+		}
+		fmt.Fprintf(&b, "| %d | %s | %s | %.0f%% | %s |\n",
+			i+1, markdownCell(r.Claim), verdict, displayFactuality(r)*100, markdownCell(source))
+	}
+
+	b.WriteString("\n## Сводка\n\n")
+	fmt.Fprintf(&b, "- Всего утверждений: %d\n", s.TotalClaims)
+	fmt.Fprintf(&b, "- Подтверждено: %d\n", s.Supported)
+	fmt.Fprintf(&b, "- Опровергнуто: %d\n", s.Refuted)
+	fmt.Fprintf(&b, "- Спорно: %d\n", s.Disputed)
+	fmt.Fprintf(&b, "- Непроверяемо: %d\n", s.Unverifiable)
+	fmt.Fprintf(&b, "- Ошибки проверки: %d\n", s.Errors)
+	fmt.Fprintf(&b, "- Возможно устарело: %d\n", s.PossiblyOutdated)
+	fmt.Fprintf(&b, "- Доля галлюцинаций: %.1f%%\n", s.HallucinationRate*100)
+
+	risk := analysis.Risk
+	fmt.Fprintf(&b, "\n## Риск ответа: %.0f/100 (%s)\n\n", risk.Score, riskLevelLabel(risk.Level))
+	for _, f := range risk.Factors {
+		fmt.Fprintf(&b, "- +%.1f — %s (%d)\n", f.Points, f.Name, f.Count)
+	}
+
+	if pr := analysis.Policy; pr != nil {
+		fmt.Fprintf(&b, "\n## Политика %s: %s\n\n", pr.Policy, pr.Status)
+		for _, v := range pr.Violations {
+			fmt.Fprintf(&b, "- **%s** `%s`: %s (значение: %g)\n", v.Severity, v.Rule, v.Message, v.Actual)
+		}
+	}
+
+	return b.String()
+}
+
+func markdownCell(text string) string {
+	text = strings.ReplaceAll(text, "|", "\\|")
+	return strings.ReplaceAll(text, "\n", " ")
+}
+
+var htmlReport = template.Must(template.New("report").Funcs(template.FuncMap{
+	"inc":    func(i int) int { return i + 1 },
+	"pct":    func(x float64) string { return fmt.Sprintf("%.0f%%", x*100) },
+	"fact":   displayFactuality,
+	"label":  verdictLabel,
+	"diff":   mismatchText,
+	"riskRU": riskLevelLabel,
+	"newline": func(s string) template.HTML {
+		return template.HTML(strings.ReplaceAll(template.HTMLEscapeString(s), "\n", "<br>"))
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="ru">
+<head>
+<meta charset="utf-8">
+<title>Результаты проверки</title>
+<style>
+body { font-family: sans-serif; max-width: 900px; margin: 2em auto; color: #1f2328; }
+.response { line-height: 1.7; padding: 1em; background: #f6f8fa; border-radius: 6px; }
+.refuted { background: #ffd7d5; color: #82071e; text-decoration: underline wavy #cf222e; }
+.disputed, .unverifiable { background: #fff1c2; color: #7d4e00; }
+.supported { background: #dafbe1; color: #116329; }
+sup a { color: #57606a; text-decoration: none; font-size: 0.75em; }
+.claim { margin: 1em 0; padding: 0.5em 1em; border-left: 4px solid #d0d7de; }
+.claim.refuted, .claim.disputed, .claim.unverifiable, .claim.supported { text-decoration: none; }
+.claim.refuted { border-color: #cf222e; } .claim.supported { border-color: #1a7f37; }
+.claim.disputed, .claim.unverifiable { border-color: #bf8700; }
+.quote { color: #57606a; font-style: italic; }
+.mismatches { color: #cf222e; }
+</style>
+</head>
+<body>
+<h1>Результаты проверки</h1>
+{{if .Analysis.Query}}<p><b>Вопрос:</b> {{.Analysis.Query}}</p>{{end}}
+<div class="response">{{range .Segments}}{{if ge .Claim 0}}<span class="{{.Verdict}}">{{newline .Text}}</span>{{else}}{{newline .Text}}{{end}}{{range .Footnotes}}<sup><a href="#claim-{{.}}">[{{.}}]</a></sup>{{end}}{{end}}</div>
+
+{{with .Analysis.Relevance}}<h2>Релевантность</h2>
+<ul>
+<li>{{if .Addressed}}Ответ по существу вопроса{{else}}<b>Ответ не отвечает на вопрос</b>{{end}}</li>
+<li>Покрытие вопроса: {{pct .Score}}</li>
+{{if .ExpectedType}}<li>Ожидаемый тип ответа: {{.ExpectedType}}, найден: {{.AnswerFound}}</li>{{end}}
+{{if .Unrequested}}<li>Утверждения не по вопросу: {{range .Unrequested}}<a href="#claim-{{.}}">[{{.}}]</a> {{end}}</li>{{end}}
+</ul>
+{{end}}
+<h2>Утверждения</h2>
+{{range $i, $r := .Analysis.FactCheckResults}}<div class="claim {{$r.Verdict}}" id="claim-{{inc $i}}">
+<p><b>[{{inc $i}}] {{$r.Claim}}</b><br>{{label $r.Verdict}} — достоверность {{pct (fact $r)}}{{if $r.PossiblyOutdated}}, возможно устарело{{end}}</p>
+{{if $r.Reason}}<p>{{$r.Reason}}</p>{{end}}
+{{if $r.Mismatches}}<ul class="mismatches">{{range $r.Mismatches}}<li>{{diff .}}</li>{{end}}</ul>{{end}}
+{{if $r.References}}<ol>{{range $r.References}}<li>{{if .IsSupportive}}✅{{else}}❌{{end}} <a href="{{.URL}}">{{if .Domain}}{{.Domain}}{{else}}{{.URL}}{{end}}</a>{{if .KeyQuote}} <span class="quote">«{{.KeyQuote}}»</span>{{end}}</li>{{end}}</ol>
+{{else if $r.ReviewURL}}<p><a href="{{$r.ReviewURL}}">{{$r.ReviewURL}}</a></p>{{end}}
+</div>
+{{end}}
+<h2>Сводка</h2>
+<ul>
+<li>Всего утверждений: {{.Analysis.Summary.TotalClaims}}</li>
+<li>Подтверждено: {{.Analysis.Summary.Supported}}</li>
+<li>Опровергнуто: {{.Analysis.Summary.Refuted}}</li>
+<li>Спорно: {{.Analysis.Summary.Disputed}}</li>
+<li>Непроверяемо: {{.Analysis.Summary.Unverifiable}}</li>
+<li>Ошибки проверки: {{.Analysis.Summary.Errors}}</li>
+<li>Доля галлюцинаций: {{pct .Analysis.Summary.HallucinationRate}}</li>
+</ul>
+<h2>Риск ответа: {{printf "%.0f" .Analysis.Risk.Score}}/100 ({{riskRU .Analysis.Risk.Level}})</h2>
+<ul>{{range .Analysis.Risk.Factors}}<li>+{{printf "%.1f" .Points}} — {{.Name}} ({{.Count}})</li>{{end}}</ul>
+{{with .Analysis.Policy}}<h2>Политика {{.Policy}}: {{.Status}}</h2>
+<ul>{{range .Violations}}<li><b>{{.Severity}}</b> {{.Rule}}: {{.Message}} (значение: {{.Actual}})</li>{{end}}</ul>{{end}}
+</body>
+</html>
+`))
+
+func renderHTML(w io.Writer, analysis *AnalysisResult) error {
+	return htmlReport.Execute(w, struct {
+		Analysis *AnalysisResult
+		Segments []annotatedSegment
+	}{
+		Analysis: analysis,
+		Segments: annotateResponse(analysis.Response, analysis.FactCheckResults),
+	})
+}
+
+// ExportTranscript сохраняет отчёт по диалогу в .json или .md
+func ExportTranscript(report *TranscriptReport, path string) error {
+	var data []byte
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		var err error
+		data, err = json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("ошибка сериализации: %w", err)
+		}
+	case ".md":
+		data = []byte(renderTranscriptMarkdown(report))
+	default:
+		return fmt.Errorf("неизвестный формат %q: для диалога поддерживаются .json и .md", filepath.Ext(path))
+	}
+
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("не удалось создать папку: %w", err)
+		}
+	}
+
+	return os.WriteFile(path, data, 0o644)
+}
+
+func renderTranscriptMarkdown(report *TranscriptReport) string {
+	var b strings.Builder
+	s := report.Summary
+
+	fmt.Fprintf(&b, "# Проверка диалога %s\n\n", report.File)
+	b.WriteString("| Реплика | Вопрос | Утверждений | Опровергнуто | Риск | По вопросу |\n")
+	b.WriteString("|---|---|---|---|---|---|\n")
+	for _, t := range report.Turns {
+		if t.Analysis == nil {
+			fmt.Fprintf(&b, "| %d | %s | — | — | — | %s |\n", t.Message, markdownCell(truncateText(t.Query, 60)), t.Error)
+			continue
+		}
+		addressed := "—"
+		if rel := t.Analysis.Relevance; rel != nil {
+			addressed = fmt.Sprintf("%t", rel.Addressed)
+		}
+		fmt.Fprintf(&b, "| %d | %s | %d | %d | %.0f | %s |\n", t.Message, markdownCell(truncateText(t.Query, 60)),
+			t.Analysis.Summary.TotalClaims, t.Analysis.Summary.Refuted, t.Analysis.Risk.Score, addressed)
+	}
+
+	b.WriteString("\n## Итого по диалогу\n\n")
+	fmt.Fprintf(&b, "- Всего утверждений: %d\n", s.TotalClaims)
+	fmt.Fprintf(&b, "- Подтверждено: %d\n", s.Supported)
+	fmt.Fprintf(&b, "- Опровергнуто: %d\n", s.Refuted)
+	fmt.Fprintf(&b, "- Доля галлюцинаций: %.1f%%\n", s.HallucinationRate*100)
+	fmt.Fprintf(&b, "- Наибольший риск ответа: %.0f/100\n", report.MaxRisk)
+	if report.Policy != "" {
+		fmt.Fprintf(&b, "- Политика: %s\n", report.Policy)
+	}
+
+	for _, t := range report.Turns {
+		if t.Analysis == nil {
+			continue
+		}
+		fmt.Fprintf(&b, "\n---\n\n## Реплика %d\n\n", t.Message)
+		b.WriteString(renderMarkdown(t.Analysis))
+	}
+
+	return b.String()
+}
+
+// ExportCompare сохраняет сравнение ответов в .json или .md
+func ExportCompare(report *CompareReport, path string) error {
+	var data []byte
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		var err error
+		data, err = json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("ошибка сериализации: %w", err)
+		}
+	case ".md":
+		data = []byte(renderCompareMarkdown(report))
+	default:
+		return fmt.Errorf("неизвестный формат %q: для сравнения поддерживаются .json и .md", filepath.Ext(path))
+	}
+
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("не удалось создать папку: %w", err)
+		}
+	}
+
+	return os.WriteFile(path, data, 0o644)
+}
+
+func renderCompareMarkdown(report *CompareReport) string {
+	var b strings.Builder
+
+	b.WriteString("# Сравнение ответов\n\n")
+	if report.Query != "" {
+		fmt.Fprintf(&b, "**Вопрос:** %s\n\n", report.Query)
+	}
+
+	b.WriteString("## Рейтинг\n\n")
+	b.WriteString("| Место | Модель | Утверждений | Опровергнуто | Доля галлюцинаций | Риск |\n")
+	b.WriteString("|---|---|---|---|---|---|\n")
+	for _, r := range report.Responses {
+		if r.Analysis == nil {
+			continue
+		}
+		s := r.Analysis.Summary
+		fmt.Fprintf(&b, "| %d | %s | %d | %d | %.1f%% | %.0f |\n", r.Rank, markdownCell(r.Label),
+			s.TotalClaims, s.Refuted, s.HallucinationRate*100, r.Analysis.Risk.Score)
+	}
+	for _, r := range report.Responses {
+		if r.Error != "" {
+			fmt.Fprintf(&b, "| — | %s | — | — | — | %s |\n", markdownCell(r.Label), r.Error)
+		}
+	}
+
+	b.WriteString("\n## Утверждения по моделям\n\n| Утверждение |")
+	for _, r := range report.Responses {
+		fmt.Fprintf(&b, " %s |", markdownCell(r.Label))
+	}
+	b.WriteString("\n|---|" + strings.Repeat("---|", len(report.Responses)) + "\n")
+	for _, row := range report.Rows {
+		claim := markdownCell(row.Claim)
+		if row.Disagree != "" {
+			claim = "⚡ " + claim
+		}
+		fmt.Fprintf(&b, "| %s |", claim)
+		for _, v := range row.Verdicts {
+			fmt.Fprintf(&b, " %s |", verdictShort(v))
+		}
+		b.WriteString("\n")
+	}
+
+	if disagreements := report.Disagreements(); len(disagreements) > 0 {
+		b.WriteString("\n## Расхождения\n")
+		for _, row := range disagreements {
+			fmt.Fprintf(&b, "\n**%s** — %s\n\n", markdownCell(row.Claim), disagreeLabel(row.Disagree))
+			for i, claim := range row.Claims {
+				if claim != "" {
+					fmt.Fprintf(&b, "- %s: %s (%s)\n", report.Responses[i].Label, claim, verdictLabel(row.Verdicts[i]))
+				}
+			}
+		}
+	}
+
+	return b.String()
+}
+
+// ExportEvalReport сохраняет прогон оценки с предсказаниями по каждому утверждению в .json
+func ExportEvalReport(report *EvalReport, path string) error {
+	if ext := strings.ToLower(filepath.Ext(path)); ext != ".json" {
+		return fmt.Errorf("неизвестный формат %q: для оценки поддерживается .json", ext)
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("ошибка сериализации: %w", err)
+	}
+
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("не удалось создать папку: %w", err)
+		}
+	}
+
+	return os.WriteFile(path, data, 0o644)
+}
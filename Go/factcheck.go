@@ -10,19 +10,55 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 )
 
+// jinaGroundingURL - адрес Jina Grounding API; в тестах подменяется заглушкой
+var jinaGroundingURL = "https://g.jina.ai/"
+
+// jinaRequestInterval - минимальный промежуток между запросами к Jina (лимит частоты API)
+const jinaRequestInterval = 500 * time.Millisecond
+
+// requestPacer выдерживает интервал между началами запросов из любых горутин
+type requestPacer struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+// jinaPacer общий для всех клиентов: ключ и лимит одни на процесс, а проверки
+// идут параллельно (потоковый режим, сервер, фоновые задачи)
+var jinaPacer = &requestPacer{interval: jinaRequestInterval}
+
+// wait ждёт, пока можно отправить следующий запрос
+func (p *requestPacer) wait() {
+	p.mu.Lock()
+	start := time.Now()
+	if p.next.After(start) {
+		start = p.next
+	}
+	p.next = start.Add(p.interval)
+	p.mu.Unlock()
+	time.Sleep(time.Until(start))
+}
+
 type JinaClient struct {
 	apiKey     string
 	baseURL    string
 	httpClient *http.Client
+
+	// ReferenceDate - дата, на которую проверяются зависящие от времени утверждения
+	ReferenceDate string
+
+	// OnResult вызывается после проверки каждого утверждения (i - индекс в claims)
+	OnResult func(i int, result FactCheckResult)
 }
 
 func NewJinaClient(apiKey string) *JinaClient {
 	return &JinaClient{
 		apiKey:  apiKey,
-		baseURL: "https://g.jina.ai/",
+		baseURL: jinaGroundingURL,
 		httpClient: &http.Client{
 			Timeout: 60 * time.Second,
 		},
@@ -54,6 +90,7 @@ func (j *JinaClient) checkViaPost(claim string) ([]byte, int, error) {
 	if err != nil {
 		return nil, 0, err
 	}
+	jinaPacer.wait()
 	req.Header.Set("Authorization", "Bearer "+j.apiKey)
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept", "application/json")
@@ -75,6 +112,7 @@ func (j *JinaClient) checkViaGet(claim string) ([]byte, int, error) {
 	if err != nil {
 		return nil, 0, err
 	}
+	jinaPacer.wait()
 	req.Header.Set("Authorization", "Bearer "+j.apiKey)
 	req.Header.Set("Accept", "application/json")
 
@@ -91,6 +129,9 @@ func (j *JinaClient) checkViaGet(claim string) ([]byte, int, error) {
 func (j *JinaClient) CheckClaim(claim string) (FactCheckResult, error) {
 	// Санитизируем перед отправкой
 	sanitized := sanitizeClaim(claim)
+	if j.ReferenceDate != "" && IsTimeSensitive(claim) {
+		sanitized += " (as of " + j.ReferenceDate + ")"
+	}
 
 	body, status, err := j.checkViaPost(sanitized)
 	if err != nil {
@@ -132,20 +173,29 @@ func (j *JinaClient) CheckClaim(claim string) (FactCheckResult, error) {
 		}
 	}
 
-	var sourceURL, sourceQuote string
+	references := make([]Reference, 0, len(jinaResponse.Data.References))
 	for _, ref := range jinaResponse.Data.References {
+		references = append(references, Reference{
+			URL:          ref.URL,
+			KeyQuote:     ref.KeyQuote,
+			IsSupportive: ref.IsSupportive,
+		})
+	}
+
+	var sourceURL, sourceQuote string
+	for _, ref := range references {
 		if ref.IsSupportive {
 			sourceURL = ref.URL
 			sourceQuote = ref.KeyQuote
 			break
 		}
 	}
-	if sourceURL == "" && len(jinaResponse.Data.References) > 0 {
-		sourceURL = jinaResponse.Data.References[0].URL
-		sourceQuote = jinaResponse.Data.References[0].KeyQuote
+	if sourceURL == "" && len(references) > 0 {
+		sourceURL = references[0].URL
+		sourceQuote = references[0].KeyQuote
 	}
 
-	return FactCheckResult{
+	result := FactCheckResult{
 		Claim:      claim, // показываем оригинал пользователю
 		Found:      true,
 		Result:     jinaResponse.Data.Result,
@@ -154,25 +204,43 @@ func (j *JinaClient) CheckClaim(claim string) (FactCheckResult, error) {
 		ReviewURL:  sourceURL,
 		KeyQuote:   sourceQuote,
 		Confidence: jinaResponse.Data.Factuality,
-	}, nil
+		Verifier:   "jina",
+		References: references,
+	}
+	result.SupportingCount, result.RefutingCount, result.SupportRatio = countSupport(references)
+
+	return result, nil
+}
+
+// countSupport считает подтверждающие и опровергающие источники
+// и долю подтверждающих среди всех
+func countSupport(references []Reference) (supporting, refuting int, ratio float64) {
+	for _, ref := range references {
+		if ref.IsSupportive {
+			supporting++
+		} else {
+			refuting++
+		}
+	}
+	if total := supporting + refuting; total > 0 {
+		ratio = float64(supporting) / float64(total)
+	}
+	return supporting, refuting, ratio
 }
 
 func (j *JinaClient) CheckClaims(claims []string) ([]FactCheckResult, error) {
 	results := make([]FactCheckResult, 0, len(claims))
 
 	for i, claim := range claims {
-		fmt.Printf("   [%d/%d] Проверка: %s\n", i+1, len(claims), claim)
-
+		started := time.Now()
 		result, err := j.CheckClaim(claim)
 		if err != nil {
-			fmt.Printf("   ⚠️  Ошибка: %v\n", err)
-			results = append(results, FactCheckResult{Claim: claim, Found: false})
-		} else {
-			results = append(results, result)
+			result = FactCheckResult{Claim: claim, Found: false, Verifier: "jina", Error: err.Error()}
 		}
-
-		if i < len(claims)-1 {
-			time.Sleep(500 * time.Millisecond)
+		result.DurationMs = time.Since(started).Milliseconds()
+		results = append(results, result)
+		if j.OnResult != nil {
+			j.OnResult(i, result)
 		}
 	}
 
@@ -181,14 +249,28 @@ func (j *JinaClient) CheckClaims(claims []string) ([]FactCheckResult, error) {
 
 func BuildSummary(results []FactCheckResult) ResultSummary {
 	summary := ResultSummary{TotalClaims: len(results)}
+	thresholds := DefaultVerdictThresholds()
 
 	for _, r := range results {
-		if r.Found && r.Result {
-			summary.ClaimsFound++
-		} else {
-			summary.ClaimsNotFound++
-			summary.PotentialHallucinations++
+		switch thresholds.Classify(r) {
+		case VerdictSupported:
+			summary.Supported++
+		case VerdictRefuted:
+			summary.Refuted++
+		case VerdictDisputed:
+			summary.Disputed++
+		case VerdictUnverifiable:
+			summary.Unverifiable++
+		case VerdictError:
+			summary.Errors++
 		}
+		if r.PossiblyOutdated {
+			summary.PossiblyOutdated++
+		}
+	}
+
+	if checked := summary.Supported + summary.Refuted + summary.Disputed; checked > 0 {
+		summary.HallucinationRate = float64(summary.Refuted) / float64(checked)
 	}
 
 	return summary
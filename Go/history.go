@@ -0,0 +1,455 @@
+// Go/history.go
+
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/muesli/termenv"
+	_ "modernc.org/sqlite"
+)
+
+const (
+	historyFile         = "data/history.db"
+	defaultHistoryLimit = 20
+	historyStemMin      = 4
+)
+
+// errHistoryNotFound - в истории нет записи с таким номером
+var errHistoryNotFound = errors.New("запись истории не найдена")
+
+// historyMigrations - схема истории по версиям; номер последней
+// применённой хранится в PRAGMA user_version. Новые версии только дописываются.
+var historyMigrations = []string{
+	`CREATE TABLE analyses (
+		id                 INTEGER PRIMARY KEY AUTOINCREMENT,
+		created_at         TEXT    NOT NULL,
+		query              TEXT    NOT NULL DEFAULT '',
+		response           TEXT    NOT NULL,
+		total_claims       INTEGER NOT NULL,
+		refuted            INTEGER NOT NULL,
+		hallucination_rate REAL    NOT NULL,
+		risk_score         REAL    NOT NULL,
+		risk_level         TEXT    NOT NULL DEFAULT '',
+		policy_status      TEXT    NOT NULL DEFAULT '',
+		duration_ms        INTEGER NOT NULL DEFAULT 0,
+		result             TEXT    NOT NULL
+	);
+	CREATE INDEX analyses_created_at ON analyses(created_at);
+	CREATE TABLE claims (
+		analysis_id INTEGER NOT NULL REFERENCES analyses(id) ON DELETE CASCADE,
+		position    INTEGER NOT NULL,
+		claim       TEXT    NOT NULL,
+		verdict     TEXT    NOT NULL,
+		verifier    TEXT    NOT NULL DEFAULT '',
+		claim_type  TEXT    NOT NULL DEFAULT '',
+		factuality  REAL    NOT NULL,
+		confidence  REAL    NOT NULL,
+		source      TEXT    NOT NULL DEFAULT '',
+		duration_ms INTEGER NOT NULL DEFAULT 0,
+		PRIMARY KEY (analysis_id, position)
+	);
+	CREATE INDEX claims_verdict ON claims(verdict);
+	CREATE VIRTUAL TABLE history_fts USING fts5(query, response, claims, tokenize = 'unicode61 remove_diacritics 2');`,
+
+	`ALTER TABLE analyses ADD COLUMN model TEXT NOT NULL DEFAULT '';
+	ALTER TABLE analyses ADD COLUMN topic TEXT NOT NULL DEFAULT '';
+	CREATE INDEX analyses_model ON analyses(model);`,
+
+	`CREATE TABLE eval_runs (
+		id         INTEGER PRIMARY KEY AUTOINCREMENT,
+		created_at TEXT    NOT NULL,
+		name       TEXT    NOT NULL DEFAULT '',
+		dataset    TEXT    NOT NULL,
+		claims     INTEGER NOT NULL,
+		accuracy   REAL    NOT NULL,
+		macro_f1   REAL    NOT NULL,
+		refuted_f1 REAL    NOT NULL,
+		ece        REAL    NOT NULL,
+		report     TEXT    NOT NULL
+	);`,
+
+	`ALTER TABLE claims ADD COLUMN raw_confidence REAL;
+	CREATE TABLE claim_labels (
+		analysis_id INTEGER NOT NULL,
+		position    INTEGER NOT NULL,
+		correct     INTEGER NOT NULL,
+		labeled_at  TEXT    NOT NULL,
+		PRIMARY KEY (analysis_id, position),
+		FOREIGN KEY (analysis_id, position) REFERENCES claims(analysis_id, position) ON DELETE CASCADE
+	);`,
+}
+
+// HistoryStore - история проверок во встроенной SQLite
+type HistoryStore struct {
+	db *sql.DB
+}
+
+// HistoryEntry - запись истории для списков и поиска; Snippet - найденный фрагмент
+type HistoryEntry struct {
+	ID                int64
+	CreatedAt         time.Time
+	Query             string
+	Response          string
+	TotalClaims       int
+	Refuted           int
+	HallucinationRate float64
+	RiskScore         float64
+	RiskLevel         string
+	PolicyStatus      string
+	DurationMs        int64
+	Snippet           string
+}
+
+// OpenHistory открывает (или создает) базу истории и доводит схему до последней версии
+func OpenHistory(path string) (*HistoryStore, error) {
+	dsn := "file:" + path + "?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_pragma=foreign_keys(1)"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось открыть историю: %w", err)
+	}
+	// Запись в SQLite всё равно идёт по одной; так не бывает SQLITE_BUSY между своими же соединениями
+	db.SetMaxOpenConns(1)
+
+	h := &HistoryStore{db: db}
+	if err := h.migrate(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return h, nil
+}
+
+func (h *HistoryStore) migrate() error {
+	var version int
+	if err := h.db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return fmt.Errorf("ошибка чтения версии истории: %w", err)
+	}
+	for v := version; v < len(historyMigrations); v++ {
+		tx, err := h.db.Begin()
+		if err != nil {
+			return fmt.Errorf("ошибка обновления истории: %w", err)
+		}
+		if _, err := tx.Exec(historyMigrations[v]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("ошибка обновления истории до версии %d: %w", v+1, err)
+		}
+		if _, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", v+1)); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("ошибка обновления истории до версии %d: %w", v+1, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("ошибка обновления истории до версии %d: %w", v+1, err)
+		}
+	}
+	return nil
+}
+
+// Close закрывает базу
+func (h *HistoryStore) Close() error {
+	return h.db.Close()
+}
+
+// Save сохраняет проверку целиком и по утверждениям; номер записи
+// возвращается и записывается в analysis.HistoryID
+func (h *HistoryStore) Save(analysis *AnalysisResult) (int64, error) {
+	result, err := json.Marshal(analysis)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка сериализации: %w", err)
+	}
+	policyStatus := ""
+	if analysis.Policy != nil {
+		policyStatus = analysis.Policy.Status
+	}
+	createdAt := analysis.CheckedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now().UTC()
+	}
+
+	tx, err := h.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`INSERT INTO analyses
+		(created_at, query, response, total_claims, refuted, hallucination_rate, risk_score, risk_level, policy_status, duration_ms, model, topic, result)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		createdAt.Format(time.RFC3339Nano), analysis.Query, analysis.Response,
+		analysis.Summary.TotalClaims, analysis.Summary.Refuted, analysis.Summary.HallucinationRate,
+		analysis.Risk.Score, analysis.Risk.Level, policyStatus, analysis.DurationMs,
+		analysis.Model, analysis.Topic, string(result))
+	if err != nil {
+		return 0, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	claims := make([]string, 0, len(analysis.FactCheckResults))
+	for i, r := range analysis.FactCheckResults {
+		// Калибровка строится по исходным оценкам, поэтому они хранятся отдельно
+		rawConfidence := r.Confidence
+		if r.Calibration != "" {
+			rawConfidence = r.RawConfidence
+		}
+		_, err := tx.Exec(`INSERT INTO claims
+			(analysis_id, position, claim, verdict, verifier, claim_type, factuality, confidence, raw_confidence, source, duration_ms)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			id, i+1, r.Claim, string(r.Verdict), r.Verifier, r.ClaimType, r.Factuality, r.Confidence, rawConfidence, r.ReviewURL, r.DurationMs)
+		if err != nil {
+			return 0, err
+		}
+		claims = append(claims, r.Claim)
+	}
+
+	if _, err := tx.Exec("INSERT INTO history_fts (rowid, query, response, claims) VALUES (?, ?, ?, ?)",
+		id, analysis.Query, analysis.Response, strings.Join(claims, "\n")); err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	analysis.HistoryID = id
+	return id, nil
+}
+
+const historyColumns = `a.id, a.created_at, a.query, a.response, a.total_claims, a.refuted,
+	a.hallucination_rate, a.risk_score, a.risk_level, a.policy_status, a.duration_ms`
+
+// Recent возвращает не больше limit последних проверок, новые первыми
+func (h *HistoryStore) Recent(limit int) ([]HistoryEntry, error) {
+	rows, err := h.db.Query(`SELECT `+historyColumns+`, '' FROM analyses a ORDER BY a.id DESC LIMIT ?`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения истории: %w", err)
+	}
+	return scanHistoryEntries(rows)
+}
+
+// Search - полнотекстовый поиск по вопросам, ответам и утверждениям.
+// Слова ищутся без окончания, чтобы находились другие падежи:
+// "битва" найдёт и "битве".
+func (h *HistoryStore) Search(text string, limit int) ([]HistoryEntry, error) {
+	match := historyFTSQuery(text)
+	if match == "" {
+		return nil, fmt.Errorf("в запросе нет слов для поиска")
+	}
+	rows, err := h.db.Query(`SELECT `+historyColumns+`, snippet(history_fts, -1, '«', '»', '…', 12)
+		FROM history_fts JOIN analyses a ON a.id = history_fts.rowid
+		WHERE history_fts MATCH ? ORDER BY rank LIMIT ?`, match, limit)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка поиска: %w", err)
+	}
+	return scanHistoryEntries(rows)
+}
+
+func scanHistoryEntries(rows *sql.Rows) ([]HistoryEntry, error) {
+	defer rows.Close()
+	var entries []HistoryEntry
+	for rows.Next() {
+		var e HistoryEntry
+		var createdAt string
+		if err := rows.Scan(&e.ID, &createdAt, &e.Query, &e.Response, &e.TotalClaims, &e.Refuted,
+			&e.HallucinationRate, &e.RiskScore, &e.RiskLevel, &e.PolicyStatus, &e.DurationMs, &e.Snippet); err != nil {
+			return nil, fmt.Errorf("ошибка чтения истории: %w", err)
+		}
+		e.CreatedAt, _ = time.Parse(time.RFC3339Nano, createdAt)
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// Get возвращает сохранённую проверку целиком
+func (h *HistoryStore) Get(id int64) (*AnalysisResult, error) {
+	var result string
+	err := h.db.QueryRow("SELECT result FROM analyses WHERE id = ?", id).Scan(&result)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("#%d: %w", id, errHistoryNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения истории: %w", err)
+	}
+
+	var analysis AnalysisResult
+	if err := json.Unmarshal([]byte(result), &analysis); err != nil {
+		return nil, fmt.Errorf("запись #%d повреждена: %w", id, err)
+	}
+	analysis.HistoryID = id
+	return &analysis, nil
+}
+
+// historyFTSQuery превращает свободный текст в запрос FTS5: каждое слово -
+// отдельный префикс в кавычках, так что спецсимволы FTS не мешают
+func historyFTSQuery(text string) string {
+	var terms []string
+	for _, w := range tokenizeWords(strings.ToLower(text)) {
+		runes := []rune(w)
+		if len(runes) == 0 || !(unicode.IsLetter(runes[0]) || unicode.IsDigit(runes[0])) {
+			continue
+		}
+		// Отбрасываем до двух последних букв, но оставляем не меньше четырёх
+		if n := max(historyStemMin, len(runes)-2); len(runes) > n {
+			w = string(runes[:n])
+		}
+		terms = append(terms, `"`+w+`"*`)
+	}
+	return strings.Join(terms, " ")
+}
+
+// sharedHistory - история, общая для всех проверок процесса; открывается при первом обращении
+var sharedHistory = sync.OnceValues(func() (*HistoryStore, error) {
+	return OpenHistory(historyFile)
+})
+
+// attachHistory подключает историю к пайплайну; без неё проверка всё равно работает
+func attachHistory(analyzer *Analyzer) *Analyzer {
+	history, err := sharedHistory()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "  ⚠️  История недоступна: %v\n", err)
+		return analyzer
+	}
+	analyzer.History = history
+	return analyzer
+}
+
+// runHistory - /history [текст]: последние проверки или поиск по ним
+func runHistory(parts []string, p termenv.Profile) {
+	colorErr := p.Color("#FF6B6B")
+	colorDim := p.Color("#8B949E")
+
+	history, err := sharedHistory()
+	if err != nil {
+		fmt.Println(termenv.String(fmt.Sprintf("  ❌ История недоступна: %v", err)).Foreground(colorErr))
+		return
+	}
+
+	var entries []HistoryEntry
+	if text := strings.Join(parts[1:], " "); text != "" {
+		entries, err = history.Search(text, defaultHistoryLimit)
+	} else {
+		entries, err = history.Recent(defaultHistoryLimit)
+	}
+	if err != nil {
+		fmt.Println(termenv.String(fmt.Sprintf("  ❌ %v", err)).Foreground(colorErr))
+		return
+	}
+	if len(entries) == 0 {
+		fmt.Println(termenv.String("  История пуста или ничего не найдено").Foreground(colorDim))
+		return
+	}
+	printHistory(entries)
+	fmt.Println(termenv.String("\n  💡 /show N — открыть проверку, /recheck N — проверить заново").Foreground(colorDim))
+}
+
+// loadHistoryEntry читает проверку по номеру из аргументов /show и /recheck
+func loadHistoryEntry(parts []string, command string, p termenv.Profile) *AnalysisResult {
+	colorErr := p.Color("#FF6B6B")
+
+	var id int64
+	if len(parts) > 1 {
+		id, _ = strconv.ParseInt(strings.TrimPrefix(parts[1], "#"), 10, 64)
+	}
+	if id < 1 {
+		fmt.Println(termenv.String(fmt.Sprintf("  ❌ Укажите номер из /history: %s N", command)).Foreground(colorErr))
+		return nil
+	}
+
+	history, err := sharedHistory()
+	if err != nil {
+		fmt.Println(termenv.String(fmt.Sprintf("  ❌ История недоступна: %v", err)).Foreground(colorErr))
+		return nil
+	}
+	analysis, err := history.Get(id)
+	if err != nil {
+		fmt.Println(termenv.String(fmt.Sprintf("  ❌ %v", err)).Foreground(colorErr))
+		return nil
+	}
+	return analysis
+}
+
+// runRecheck проверяет сохранённый ответ заново и показывает, что изменилось
+func runRecheck(previous *AnalysisResult, opts checkOptions, p termenv.Profile) *AnalysisResult {
+	analysis, err := runFull(previous.Query, previous.Response, opts, p)
+	if err != nil {
+		return nil
+	}
+	printRecheckDiff(previous, analysis)
+	return analysis
+}
+
+// printHistory - таблица записей истории
+func printHistory(entries []HistoryEntry) {
+	p := termenv.ColorProfile()
+	colorHeader := p.Color("#00BFFF")
+	colorOk := p.Color("#3FB950")
+	colorErr := p.Color("#FF6B6B")
+	colorWarn := p.Color("#D29922")
+	colorDim := p.Color("#8B949E")
+
+	fmt.Println()
+	fmt.Println(termenv.String("  ══════════════════════════════════════════").Foreground(colorHeader))
+	fmt.Println(termenv.String(fmt.Sprintf("  %-6s %-16s %5s %9s  %s", "#", "Дата", "Риск", "Опроверг.", "Вопрос / ответ")).Foreground(colorDim))
+
+	for _, e := range entries {
+		title := e.Query
+		if title == "" {
+			title = e.Response
+		}
+		risk := termenv.String(fmt.Sprintf("%5.0f", e.RiskScore))
+		switch e.RiskLevel {
+		case "high", "critical":
+			risk = risk.Foreground(colorErr)
+		case "medium":
+			risk = risk.Foreground(colorWarn)
+		default:
+			risk = risk.Foreground(colorOk)
+		}
+		fmt.Printf("  %-6s %-16s %s %9s  %s\n",
+			fmt.Sprintf("#%d", e.ID), e.CreatedAt.Local().Format("2006-01-02 15:04"), risk,
+			fmt.Sprintf("%d/%d", e.Refuted, e.TotalClaims), truncateText(title, 60))
+		if e.Snippet != "" {
+			fmt.Println(termenv.String("         " + truncateText(e.Snippet, 100)).Foreground(colorDim))
+		}
+	}
+}
+
+// printRecheckDiff - какие вердикты изменились после повторной проверки
+func printRecheckDiff(previous, current *AnalysisResult) {
+	p := termenv.ColorProfile()
+	colorHeader := p.Color("#00BFFF")
+	colorWarn := p.Color("#D29922")
+	colorDim := p.Color("#8B949E")
+
+	before := make(map[string]Verdict, len(previous.FactCheckResults))
+	for _, r := range previous.FactCheckResults {
+		before[strings.ToLower(r.Claim)] = r.Verdict
+	}
+
+	fmt.Println(termenv.String(fmt.Sprintf("\n  🔁 Сравнение с проверкой #%d", previous.HistoryID)).Foreground(colorHeader).Bold())
+	changed := 0
+	for _, r := range current.FactCheckResults {
+		old, ok := before[strings.ToLower(r.Claim)]
+		switch {
+		case !ok:
+			fmt.Println(termenv.String(fmt.Sprintf("     + %s %s", verdictIcon(r.Verdict), truncateText(r.Claim, 80))).Foreground(colorWarn))
+			changed++
+		case old != r.Verdict:
+			fmt.Println(termenv.String(fmt.Sprintf("     %s → %s %s", verdictLabel(old), verdictLabel(r.Verdict), truncateText(r.Claim, 70))).Foreground(colorWarn))
+			changed++
+		}
+	}
+	if changed == 0 {
+		fmt.Println(termenv.String("     Вердикты не изменились").Foreground(colorDim))
+	}
+	fmt.Println(termenv.String(fmt.Sprintf("     Риск: %.0f → %.0f", previous.Risk.Score, current.Risk.Score)).Foreground(colorDim))
+}
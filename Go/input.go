@@ -0,0 +1,144 @@
+// Go/input.go
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// maxInputSize - предел размера ответа из файла, stdin или одной строки REPL
+const maxInputSize = 4 << 20
+
+// pasteSentinel завершает многострочный ввод в REPL
+const pasteSentinel = "/end"
+
+var (
+	htmlDropPattern  = regexp.MustCompile(`(?is)<(script|style|pre)\b[^>]*>.*?</(?:script|style|pre)>`)
+	htmlBlockPattern = regexp.MustCompile(`(?i)<br\s*/?>|</?(?:p|div|li|ul|ol|tr|table|h[1-6]|blockquote|section|article)\b[^>]*>`)
+	htmlCellPattern  = regexp.MustCompile(`(?i)</t[dh]>`)
+	htmlTagPattern   = regexp.MustCompile(`</?[a-zA-Z][^>]*>`)
+
+	fencePattern      = regexp.MustCompile("^\\s*(```|~~~)")
+	tableRulePattern  = regexp.MustCompile(`^\s*\|?\s*:?-{3,}:?\s*(\|\s*:?-{3,}:?\s*)*\|?\s*$`)
+	headingPattern    = regexp.MustCompile(`^\s*#{1,6}\s+`)
+	quotePattern      = regexp.MustCompile(`^\s*(?:>\s?)+`)
+	listMarkerPattern = regexp.MustCompile(`^\s*(?:[-*+•]|\d+[.)])\s+`)
+	rulePattern       = regexp.MustCompile(`^\s*(?:(?:-\s*){3,}|(?:\*\s*){3,}|(?:_\s*){3,})$`)
+	imagePattern      = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+	linkPattern       = regexp.MustCompile(`\[([^\]]+)\]\([^)]*\)`)
+	emphasisPattern   = regexp.MustCompile("\\*\\*|__|~~|`")
+	blankLinesPattern = regexp.MustCompile(`\n{3,}`)
+)
+
+// NormalizeInput превращает ответ в Markdown или HTML в обычный текст для
+// извлечения утверждений: блоки кода выбрасываются (Go-код проверяется
+// отдельно до нормализации), у таблиц, списков и заголовков снимается
+// разметка, теги удаляются, сущности раскодируются.
+func NormalizeInput(text string) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+
+	// HTML
+	text = htmlDropPattern.ReplaceAllString(text, "\n")
+	text = htmlCellPattern.ReplaceAllString(text, " | ")
+	text = htmlBlockPattern.ReplaceAllString(text, "\n")
+	text = htmlTagPattern.ReplaceAllString(text, "")
+	text = html.UnescapeString(text)
+
+	// Markdown построчно
+	var lines []string
+	inFence := false
+	for _, line := range strings.Split(text, "\n") {
+		if fencePattern.MatchString(line) {
+			inFence = !inFence
+			continue
+		}
+		if inFence || tableRulePattern.MatchString(line) || rulePattern.MatchString(line) {
+			continue
+		}
+
+		line = headingPattern.ReplaceAllString(line, "")
+		line = quotePattern.ReplaceAllString(line, "")
+		line = listMarkerPattern.ReplaceAllString(line, "")
+		if strings.Contains(line, "|") {
+			line = tableRow(line)
+		}
+		line = imagePattern.ReplaceAllString(line, "$1")
+		line = linkPattern.ReplaceAllString(line, "$1")
+		line = emphasisPattern.ReplaceAllString(line, "")
+
+		lines = append(lines, strings.TrimSpace(line))
+	}
+
+	text = strings.Join(lines, "\n")
+	text = blankLinesPattern.ReplaceAllString(text, "\n\n")
+	return strings.TrimSpace(text)
+}
+
+// tableRow - строка таблицы как перечисление ячеек: "| Москва | 1147 |" -> "Москва, 1147"
+func tableRow(line string) string {
+	var cells []string
+	for _, cell := range strings.Split(strings.Trim(strings.TrimSpace(line), "|"), "|") {
+		if cell = strings.TrimSpace(cell); cell != "" {
+			cells = append(cells, cell)
+		}
+	}
+	return strings.Join(cells, ", ")
+}
+
+// readPaste читает строки REPL до pasteSentinel
+func readPaste(scanner *bufio.Scanner) (string, error) {
+	var b strings.Builder
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == pasteSentinel {
+			return b.String(), nil
+		}
+		if b.Len()+len(line) > maxInputSize {
+			return "", fmt.Errorf("ответ больше %d МБ", maxInputSize>>20)
+		}
+		b.WriteString(line)
+		b.WriteByte('\n')
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("ошибка чтения: %w", err)
+	}
+	// Ввод закончился без /end - проверяем то, что успели вставить
+	return b.String(), nil
+}
+
+// readInput читает ответ из файла или, если path = "-", из stdin
+func readInput(path string) (string, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return "", fmt.Errorf("не удалось открыть файл: %w", err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r, maxInputSize+1))
+	if err != nil {
+		return "", fmt.Errorf("ошибка чтения: %w", err)
+	}
+	if len(data) > maxInputSize {
+		return "", fmt.Errorf("ответ больше %d МБ", maxInputSize>>20)
+	}
+	return string(data), nil
+}
+
+// stdinPiped - stdin перенаправлен из файла или канала, а не из терминала
+func stdinPiped() bool {
+	info, err := os.Stdin.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice == 0
+}
@@ -0,0 +1,552 @@
+// Go/jobs.go
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	jobsDir           = "data/jobs"
+	defaultJobWorkers = 2
+	maxQueuedJobs     = 1000
+	maxJobItems       = 1000
+	maxJobBody        = 64 << 20
+)
+
+// Статусы задачи и её элементов
+const (
+	JobQueued   = "queued"
+	JobRunning  = "running"
+	JobDone     = "done"
+	JobFailed   = "failed"
+	JobCanceled = "canceled"
+)
+
+var (
+	errJobNotFound = errors.New("задача не найдена")
+	errJobState    = errors.New("действие недоступно в текущем статусе задачи")
+	errQueueFull   = errors.New("очередь задач заполнена")
+)
+
+// JobRequest - тело POST /api/jobs: пакет ответов на проверку
+type JobRequest struct {
+	Items []AnalyzeRequest `json:"items"`
+}
+
+// JobItem - один ответ в задаче. Результат хранится вместе с задачей,
+// чтобы пережить перезапуск, и публикуется в /api/analyses/{analysis_id}.
+type JobItem struct {
+	Request     AnalyzeRequest  `json:"request"`
+	Status      string          `json:"status"`
+	AnalysisID  string          `json:"analysis_id,omitempty"`
+	CompletedAt *time.Time      `json:"completed_at,omitempty"`
+	Analysis    *AnalysisResult `json:"analysis,omitempty"`
+	Error       string          `json:"error,omitempty"`
+}
+
+// JobProgress - счётчики задачи: элементы по статусам и проверенные утверждения
+type JobProgress struct {
+	Total    int `json:"total"`
+	Done     int `json:"done"`
+	Failed   int `json:"failed"`
+	Canceled int `json:"canceled"`
+	Claims   int `json:"claims_checked"`
+	Refuted  int `json:"refuted"`
+}
+
+// Job - пакетная проверка, выполняемая в фоне
+type Job struct {
+	ID         string      `json:"id"`
+	Status     string      `json:"status"`
+	CreatedAt  time.Time   `json:"created_at"`
+	StartedAt  *time.Time  `json:"started_at,omitempty"`
+	FinishedAt *time.Time  `json:"finished_at,omitempty"`
+	Attempts   int         `json:"attempts"`
+	Progress   JobProgress `json:"progress"`
+	Items      []JobItem   `json:"items"`
+
+	cancel bool // запрошена отмена выполняемой задачи
+}
+
+// recount пересчитывает счётчики по статусам элементов
+func (j *Job) recount() {
+	p := JobProgress{Total: len(j.Items)}
+	for _, item := range j.Items {
+		switch item.Status {
+		case JobDone:
+			p.Done++
+			if item.Analysis != nil {
+				p.Claims += item.Analysis.Summary.TotalClaims
+				p.Refuted += item.Analysis.Summary.Refuted
+			}
+		case JobFailed:
+			p.Failed++
+		case JobCanceled:
+			p.Canceled++
+		}
+	}
+	j.Progress = p
+}
+
+// snapshot - копия задачи для ответа API; результаты проверок по запросу,
+// чтобы опрос статуса большой задачи не гонял их каждый раз
+func (j *Job) snapshot(withAnalyses bool) Job {
+	c := *j
+	c.Items = make([]JobItem, len(j.Items))
+	copy(c.Items, j.Items)
+	if !withAnalyses {
+		for i := range c.Items {
+			c.Items[i].Analysis = nil
+		}
+	}
+	return c
+}
+
+// jobListItem - краткая запись для GET /api/jobs
+type jobListItem struct {
+	ID         string      `json:"id"`
+	Status     string      `json:"status"`
+	CreatedAt  time.Time   `json:"created_at"`
+	FinishedAt *time.Time  `json:"finished_at,omitempty"`
+	Progress   JobProgress `json:"progress"`
+}
+
+// JobQueue выполняет задачи не более чем в workers горутинах. Каждая задача
+// хранится JSON-файлом в dir и сохраняется после каждого элемента; при
+// запуске незавершённые задачи снова ставятся в очередь.
+type JobQueue struct {
+	mu         sync.Mutex
+	jobs       map[string]*Job
+	dir        string
+	analyzer   *Analyzer
+	policyFile string
+	pending    chan string
+	publish    func(*AnalysisRecord)
+}
+
+// NewJobQueue загружает сохранённые задачи из dir и запускает workers
+// обработчиков. Готовые результаты передаются в publish.
+func NewJobQueue(dir string, workers int, analyzer *Analyzer, policyFile string, publish func(*AnalysisRecord)) (*JobQueue, error) {
+	if workers < 1 {
+		return nil, fmt.Errorf("число обработчиков должно быть положительным")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("не удалось создать каталог задач: %w", err)
+	}
+
+	q := &JobQueue{
+		jobs:       make(map[string]*Job),
+		dir:        dir,
+		analyzer:   analyzer,
+		policyFile: policyFile,
+		publish:    publish,
+	}
+	resume, err := q.load()
+	if err != nil {
+		return nil, err
+	}
+
+	q.pending = make(chan string, maxQueuedJobs+len(resume))
+	for _, id := range resume {
+		q.pending <- id
+	}
+	if len(resume) > 0 {
+		log.Printf("Возобновлено задач: %d", len(resume))
+	}
+	for range workers {
+		go q.worker()
+	}
+	return q, nil
+}
+
+// load читает задачи с диска и возвращает незавершённые в порядке создания
+func (q *JobQueue) load() ([]string, error) {
+	paths, err := filepath.Glob(filepath.Join(q.dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения каталога задач: %w", err)
+	}
+
+	var resume []*Job
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("не удалось прочитать задачу: %w", err)
+		}
+		var job Job
+		if err := json.Unmarshal(data, &job); err != nil {
+			log.Printf("Задача %s пропущена: %v", filepath.Base(path), err)
+			continue
+		}
+		q.jobs[job.ID] = &job
+
+		for _, item := range job.Items {
+			if item.Status == JobDone && item.Analysis != nil {
+				q.publishItem(item)
+			}
+		}
+		// Прерванная остановкой сервера задача продолжается с незавершённых элементов
+		if job.Status == JobQueued || job.Status == JobRunning {
+			job.Status = JobQueued
+			resume = append(resume, &job)
+		}
+	}
+
+	sort.Slice(resume, func(i, j int) bool { return resume[i].CreatedAt.Before(resume[j].CreatedAt) })
+	ids := make([]string, len(resume))
+	for i, job := range resume {
+		ids[i] = job.ID
+	}
+	return ids, nil
+}
+
+// save записывает задачу через временный файл, чтобы сбой не оставил её
+// недописанной; вызывается под q.mu
+func (q *JobQueue) save(job *Job) {
+	data, err := json.MarshalIndent(job, "", "  ")
+	if err != nil {
+		log.Printf("Ошибка сериализации задачи %s: %v", job.ID, err)
+		return
+	}
+	path := filepath.Join(q.dir, job.ID+".json")
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		log.Printf("Не удалось сохранить задачу %s: %v", job.ID, err)
+		return
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		log.Printf("Не удалось сохранить задачу %s: %v", job.ID, err)
+	}
+}
+
+func (q *JobQueue) publishItem(item JobItem) {
+	if q.publish == nil {
+		return
+	}
+	q.publish(&AnalysisRecord{ID: item.AnalysisID, CreatedAt: *item.CompletedAt, Analysis: item.Analysis})
+}
+
+// enqueue ставит задачу в очередь; вызывается под q.mu
+func (q *JobQueue) enqueue(id string) error {
+	select {
+	case q.pending <- id:
+		return nil
+	default:
+		return errQueueFull
+	}
+}
+
+// Submit создает задачу из пакета ответов
+func (q *JobQueue) Submit(requests []AnalyzeRequest) (Job, error) {
+	job := &Job{
+		ID:        newAnalysisID(),
+		Status:    JobQueued,
+		CreatedAt: time.Now().UTC(),
+		Attempts:  1,
+		Items:     make([]JobItem, len(requests)),
+	}
+	for i, req := range requests {
+		job.Items[i] = JobItem{Request: req, Status: JobQueued}
+	}
+	job.recount()
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if err := q.enqueue(job.ID); err != nil {
+		return Job{}, err
+	}
+	q.jobs[job.ID] = job
+	q.save(job)
+	return job.snapshot(false), nil
+}
+
+// Get возвращает копию задачи
+func (q *JobQueue) Get(id string, withAnalyses bool) (Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	job, ok := q.jobs[id]
+	if !ok {
+		return Job{}, errJobNotFound
+	}
+	return job.snapshot(withAnalyses), nil
+}
+
+// List возвращает не больше limit последних задач, новые первыми
+func (q *JobQueue) List(limit int) []jobListItem {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	jobs := make([]*Job, 0, len(q.jobs))
+	for _, job := range q.jobs {
+		jobs = append(jobs, job)
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].CreatedAt.After(jobs[j].CreatedAt) })
+
+	items := make([]jobListItem, 0, min(limit, len(jobs)))
+	for _, job := range jobs[:min(limit, len(jobs))] {
+		items = append(items, jobListItem{
+			ID:         job.ID,
+			Status:     job.Status,
+			CreatedAt:  job.CreatedAt,
+			FinishedAt: job.FinishedAt,
+			Progress:   job.Progress,
+		})
+	}
+	return items
+}
+
+// Cancel отменяет задачу. Ожидающая задача отменяется сразу, выполняемая -
+// после текущего элемента: начатую проверку ответа прервать нельзя.
+func (q *JobQueue) Cancel(id string) (Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	job, ok := q.jobs[id]
+	if !ok {
+		return Job{}, errJobNotFound
+	}
+
+	switch job.Status {
+	case JobQueued:
+		q.finish(job, true)
+	case JobRunning:
+		job.cancel = true
+	default:
+		return Job{}, errJobState
+	}
+	return job.snapshot(false), nil
+}
+
+// Retry снова ставит в очередь завершившуюся с ошибкой или отменённую
+// задачу; уже проверенные элементы повторно не проверяются
+func (q *JobQueue) Retry(id string) (Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	job, ok := q.jobs[id]
+	if !ok {
+		return Job{}, errJobNotFound
+	}
+	if job.Status != JobFailed && job.Status != JobCanceled {
+		return Job{}, errJobState
+	}
+	if err := q.enqueue(job.ID); err != nil {
+		return Job{}, err
+	}
+
+	for i := range job.Items {
+		if item := &job.Items[i]; item.Status == JobFailed || item.Status == JobCanceled {
+			item.Status = JobQueued
+			item.Error = ""
+		}
+	}
+	job.Status = JobQueued
+	job.Attempts++
+	job.FinishedAt = nil
+	job.recount()
+	q.save(job)
+	return job.snapshot(false), nil
+}
+
+// finish подводит итог задачи; вызывается под q.mu
+func (q *JobQueue) finish(job *Job, canceled bool) {
+	now := time.Now().UTC()
+	failed := false
+	for i := range job.Items {
+		item := &job.Items[i]
+		if item.Status == JobQueued && canceled {
+			item.Status = JobCanceled
+		}
+		if item.Status == JobFailed {
+			failed = true
+		}
+	}
+
+	switch {
+	case canceled:
+		job.Status = JobCanceled
+	case failed:
+		job.Status = JobFailed
+	default:
+		job.Status = JobDone
+	}
+	job.FinishedAt = &now
+	job.cancel = false
+	job.recount()
+	q.save(job)
+}
+
+func (q *JobQueue) worker() {
+	for id := range q.pending {
+		q.run(id)
+	}
+}
+
+// run проверяет элементы задачи по очереди
+func (q *JobQueue) run(id string) {
+	q.mu.Lock()
+	job, ok := q.jobs[id]
+	// Задачу могли отменить, пока она ждала в очереди
+	if !ok || job.Status != JobQueued {
+		q.mu.Unlock()
+		return
+	}
+	now := time.Now().UTC()
+	job.Status = JobRunning
+	job.StartedAt = &now
+	job.recount()
+	q.save(job)
+	q.mu.Unlock()
+
+	for i := range job.Items {
+		q.mu.Lock()
+		if job.cancel {
+			q.finish(job, true)
+			q.mu.Unlock()
+			return
+		}
+		// Проверенные при прошлой попытке элементы пропускаются
+		req, status := job.Items[i].Request, job.Items[i].Status
+		q.mu.Unlock()
+		if status != JobQueued {
+			continue
+		}
+
+		opts := checkOptions{PolicyFile: q.policyFile, Model: req.Model}
+		if req.AsOf != "" {
+			opts.ReferenceDate, _ = parseReferenceDate(req.AsOf)
+		}
+		analysis, err := q.analyzer.Analyze(req.Query, req.Response, opts, func(e AnalysisEvent) {
+			if e.Type != EventClaim {
+				return
+			}
+			q.mu.Lock()
+			job.Progress.Claims++
+			if e.Result.Verdict == VerdictRefuted {
+				job.Progress.Refuted++
+			}
+			q.mu.Unlock()
+		})
+
+		q.mu.Lock()
+		item := &job.Items[i]
+		completed := time.Now().UTC()
+		item.CompletedAt = &completed
+		switch {
+		case errors.Is(err, errNoClaims):
+			// Ответ без утверждений - проверен, повторять нечего
+			item.Status = JobDone
+			item.Error = err.Error()
+		case err != nil:
+			item.Status = JobFailed
+			item.Error = err.Error()
+		default:
+			item.Status = JobDone
+			item.AnalysisID = newAnalysisID()
+			item.Analysis = analysis
+			q.publishItem(*item)
+		}
+		job.recount()
+		q.save(job)
+		q.mu.Unlock()
+	}
+
+	q.mu.Lock()
+	q.finish(job, job.cancel)
+	q.mu.Unlock()
+}
+
+func (s *Server) handleJobSubmit(w http.ResponseWriter, r *http.Request) {
+	var req JobRequest
+	if status, err := decodeJSONBody(w, r, &req, maxJobBody); err != nil {
+		writeError(w, status, err)
+		return
+	}
+	if len(req.Items) == 0 {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("поле items обязательно"))
+		return
+	}
+	if len(req.Items) > maxJobItems {
+		writeError(w, http.StatusRequestEntityTooLarge, fmt.Errorf("в задаче больше %d ответов", maxJobItems))
+		return
+	}
+	for i, item := range req.Items {
+		if _, status, err := s.validateAnalyzeRequest(item); err != nil {
+			writeError(w, status, fmt.Errorf("items[%d]: %w", i, err))
+			return
+		}
+	}
+
+	job, err := s.jobs.Submit(req.Items)
+	if err != nil {
+		writeJobError(w, err)
+		return
+	}
+	w.Header().Set("Location", "/api/jobs/"+job.ID)
+	writeJSON(w, http.StatusAccepted, job)
+}
+
+func (s *Server) handleJobList(w http.ResponseWriter, r *http.Request) {
+	limit := defaultListLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("limit должен быть положительным числом"))
+			return
+		}
+		limit = n
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"jobs": s.jobs.List(limit)})
+}
+
+// handleJobGet отдаёт статус задачи; ?results=1 - вместе с результатами проверок
+func (s *Server) handleJobGet(w http.ResponseWriter, r *http.Request) {
+	withAnalyses, _ := strconv.ParseBool(r.URL.Query().Get("results"))
+	job, err := s.jobs.Get(r.PathValue("id"), withAnalyses)
+	if err != nil {
+		writeJobError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, job)
+}
+
+func (s *Server) handleJobCancel(w http.ResponseWriter, r *http.Request) {
+	job, err := s.jobs.Cancel(r.PathValue("id"))
+	if err != nil {
+		writeJobError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, job)
+}
+
+func (s *Server) handleJobRetry(w http.ResponseWriter, r *http.Request) {
+	job, err := s.jobs.Retry(r.PathValue("id"))
+	if err != nil {
+		writeJobError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, job)
+}
+
+// writeJobError сопоставляет ошибки очереди с HTTP-статусами
+func writeJobError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, errJobNotFound):
+		writeError(w, http.StatusNotFound, err)
+	case errors.Is(err, errJobState):
+		writeError(w, http.StatusConflict, err)
+	case errors.Is(err, errQueueFull):
+		w.Header().Set("Retry-After", "60")
+		writeError(w, http.StatusServiceUnavailable, err)
+	default:
+		writeError(w, http.StatusInternalServerError, err)
+	}
+}
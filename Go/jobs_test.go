@@ -0,0 +1,102 @@
+// Go/jobs_test.go
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestJobsConcurrentItems - два обработчика проверяют разные ответы одновременно,
+// а извлекатель, как Python API в пределах одной секунды, пишет их в один файл.
+// Каждый результат должен остаться со своим вопросом, ответом и утверждениями.
+func TestJobsConcurrentItems(t *testing.T) {
+	shared := filepath.Join(t.TempDir(), "claims_shared.json")
+	var arrived sync.WaitGroup
+	arrived.Add(2)
+
+	python := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct{ Text, Query string }
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		claims := []string{strings.TrimSuffix(req.Text, ".")}
+		data, _ := json.Marshal(ClaimsData{Query: req.Query, Response: req.Text, Claims: claims, Count: len(claims)})
+		if err := os.WriteFile(shared, data, 0644); err != nil {
+			t.Error(err)
+		}
+
+		// Оба запроса должны быть в работе одновременно
+		arrived.Done()
+		arrived.Wait()
+		json.NewEncoder(w).Encode(ExtractSaveResponse{Success: true, Filename: shared, ClaimsCount: len(claims), Claims: claims})
+	}))
+	defer python.Close()
+
+	jina := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"data":{"factuality":0.9,"result":true,"references":[]}}`)
+	}))
+	defer jina.Close()
+	defer func(url string) { jinaGroundingURL = url }(jinaGroundingURL)
+	jinaGroundingURL = jina.URL + "/"
+
+	server := NewServer(NewAnalyzer(NewPythonClient(python.URL), "test"), "")
+	if err := server.StartJobs(t.TempDir(), 2); err != nil {
+		t.Fatal(err)
+	}
+
+	requests := []AnalyzeRequest{
+		{Query: "Когда основана Москва?", Response: "Москва основана в 1147 году."},
+		{Query: "Какая высота Эвереста?", Response: "Высота Эвереста 8849 метров."},
+	}
+	ids := make([]string, len(requests))
+	for i, req := range requests {
+		job, err := server.jobs.Submit([]AnalyzeRequest{req})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids[i] = job.ID
+	}
+
+	for i, id := range ids {
+		job := waitJob(t, server.jobs, id)
+		if job.Status != JobDone {
+			t.Fatalf("задача %d: статус %s, ошибка %q", i, job.Status, job.Items[0].Error)
+		}
+		want, analysis := requests[i], job.Items[0].Analysis
+		if analysis.Query != want.Query || analysis.Response != want.Response {
+			t.Errorf("задача %d: получен ответ %q на вопрос %q, ожидался %q на %q",
+				i, analysis.Response, analysis.Query, want.Response, want.Query)
+		}
+		if len(analysis.Claims) != 1 || !strings.HasPrefix(want.Response, analysis.Claims[0]) {
+			t.Errorf("задача %d: чужие утверждения %q", i, analysis.Claims)
+		}
+	}
+}
+
+// waitJob ждёт, пока задача завершится, и возвращает её с результатами
+func waitJob(t *testing.T, jobs *JobQueue, id string) Job {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		job, err := jobs.Get(id, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if job.FinishedAt != nil {
+			return job
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("задача %s не завершилась", id)
+	return Job{}
+}
@@ -4,12 +4,15 @@ package main
 
 import (
 	"bufio"
-	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
@@ -17,43 +20,22 @@ import (
 )
 
 func main() {
-	client := NewPythonClient("http://localhost:8000")
-	if client.HealthCheck() != nil {
-		fmt.Println("  🐍 Запуск Python API...")
-
-		workDir, _ := os.Getwd()
-		pythonScript := filepath.Join(workDir, "Python", "app.py")
-
-		cmd := exec.Command("python", pythonScript)
-		cmd.Stdout = os.Stdout
-		cmd.Stderr = os.Stderr
-		cmd.SysProcAttr = &syscall.SysProcAttr{
-			CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP,
-		}
-
-		if err := cmd.Start(); err != nil {
-			fmt.Println("  ❌ Не удалось запустить Python:", err)
-			fmt.Println("  💡 Запустите вручную: cd Python && python app.py")
-		} else {
-			started := false
-			for i := 0; i < 60; i++ {
-				time.Sleep(1 * time.Second)
-				if client.HealthCheck() == nil {
-					started = true
-					break
-				}
-			}
+	defer stopPythonAPI()
 
-			if started {
-				fmt.Println("  ✅ Python API готов!")
-				defer cmd.Process.Kill()
-			} else {
-				fmt.Println("  ❌ Python API не запустился за 60 секунд")
-				fmt.Println("  💡 Запустите вручную: cd Python && python app.py")
-				cmd.Process.Kill()
-			}
-		}
+	// Неинтерактивный режим: main check -r "..." — код выхода задаёт политика.
+	// Ответ, переданный через stdin без аргументов, проверяется так же.
+	args := os.Args[1:]
+	if len(args) == 0 && stdinPiped() {
+		args = []string{"check", "-f", "-"}
 	}
+	if len(args) > 0 {
+		code := runCommand(args)
+		stopPythonAPI()
+		os.Exit(code)
+	}
+
+	// В REPL сообщения о запуске Python - часть диалога
+	pythonOutput = os.Stdout
 
 	p := termenv.ColorProfile()
 	colorPrompt := p.Color("#00BFFF")
@@ -67,6 +49,9 @@ func main() {
 	fmt.Println()
 
 	scanner := bufio.NewScanner(os.Stdin)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxInputSize)
+	opts := checkOptions{PolicyFile: policyFile}
+	var last *AnalysisResult
 
 	for {
 		prompt := termenv.String(" > ").Foreground(colorPrompt).Background(colorBg).Bold()
@@ -93,16 +78,153 @@ func main() {
 			printHelp(p)
 
 		case "/check":
+			// С другими флагами ответ берётся до следующего флага, без них - до конца строки
+			query := extractFlagValue(parts, "-q")
+			file := extractFlagValue(parts, "-f")
 			response := extractFlag(parts, "-r")
-			if response == "" {
-				fmt.Println(termenv.String("  ❌ Укажите ответ ИИ: /check -r \"текст ответа\"").Foreground(colorError))
+			if query != "" || file != "" {
+				response = extractFlagValue(parts, "-r")
+			}
+
+			switch {
+			case file != "":
+				text, err := readInput(file)
+				if err != nil {
+					fmt.Println(termenv.String(fmt.Sprintf("  ❌ %v", err)).Foreground(colorError))
+					continue
+				}
+				response = text
+			case response == "":
+				fmt.Println(termenv.String(fmt.Sprintf("  📋 Вставьте ответ ИИ и завершите строкой %s", pasteSentinel)).Foreground(colorDim))
+				text, err := readPaste(scanner)
+				if err != nil {
+					fmt.Println(termenv.String(fmt.Sprintf("  ❌ %v", err)).Foreground(colorError))
+					continue
+				}
+				response = text
+			}
+			if strings.TrimSpace(response) == "" {
+				fmt.Println(termenv.String("  ❌ Укажите ответ ИИ: /check [-q \"вопрос\"] -r \"текст ответа\"").Foreground(colorError))
+				continue
+			}
+			if analysis, err := runFull(query, response, opts, p); err == nil {
+				last = analysis
+			}
+
+		case "/explain":
+			if last == nil {
+				fmt.Println(termenv.String("  ❌ Сначала выполните /check").Foreground(colorError))
+				continue
+			}
+			n := 0
+			if len(parts) > 1 {
+				n, _ = strconv.Atoi(parts[1])
+			}
+			if n < 1 || n > len(last.FactCheckResults) {
+				fmt.Println(termenv.String(fmt.Sprintf("  ❌ Укажите номер утверждения от 1 до %d: /explain N", len(last.FactCheckResults))).Foreground(colorError))
 				continue
 			}
-			runFull(response, p)
+			printExplain(n, last.FactCheckResults[n-1])
+
+		case "/asof":
+			runAsOf(parts, &opts, p)
+
+		case "/model":
+			runModel(parts, &opts, p)
 
 		case "/verify":
 			runVerify(p)
 
+		case "/correct":
+			if last == nil {
+				fmt.Println(termenv.String("  ❌ Сначала выполните /check").Foreground(colorError))
+				continue
+			}
+			if correction := runCorrect(last, len(parts) > 1 && parts[1] == "-llm", p); correction != nil {
+				last.Correction = correction
+			}
+
+		case "/export":
+			if last == nil {
+				fmt.Println(termenv.String("  ❌ Сначала выполните /check").Foreground(colorError))
+				continue
+			}
+			if len(parts) < 2 {
+				fmt.Println(termenv.String("  ❌ Укажите файл: /export report.json, report.md или report.html").Foreground(colorError))
+				continue
+			}
+			if err := ExportAnalysis(last, parts[1]); err != nil {
+				fmt.Println(termenv.String(fmt.Sprintf("  ❌ Ошибка экспорта: %v", err)).Foreground(colorError))
+				continue
+			}
+			fmt.Println(termenv.String(fmt.Sprintf("  ✅ Отчёт сохранён в %s", parts[1])).Foreground(colorDim))
+
+		case "/myth":
+			runMyth(parts, p)
+
+		case "/transcript":
+			if len(parts) < 2 {
+				fmt.Println(termenv.String("  ❌ Укажите файл диалога: /transcript chat.json [-o отчёт.md]").Foreground(colorError))
+				continue
+			}
+			report := runTranscript(parts[1], opts, p)
+			if report == nil {
+				continue
+			}
+			printTranscriptReport(report)
+			if output := extractFlagValue(parts, "-o"); output != "" {
+				if err := ExportTranscript(report, output); err != nil {
+					fmt.Println(termenv.String(fmt.Sprintf("  ❌ Ошибка экспорта: %v", err)).Foreground(colorError))
+					continue
+				}
+				fmt.Println(termenv.String(fmt.Sprintf("  ✅ Отчёт сохранён в %s", output)).Foreground(colorDim))
+			}
+
+		case "/history":
+			runHistory(parts, p)
+
+		case "/show":
+			if analysis := loadHistoryEntry(parts, "/show", p); analysis != nil {
+				fmt.Println(termenv.String(fmt.Sprintf("  📜 Проверка #%d от %s", analysis.HistoryID, analysis.CheckedAt.Local().Format("2006-01-02 15:04"))).Foreground(colorDim))
+				printResults(analysis)
+				last = analysis
+			}
+
+		case "/recheck":
+			if previous := loadHistoryEntry(parts, "/recheck", p); previous != nil {
+				if analysis := runRecheck(previous, opts, p); analysis != nil {
+					last = analysis
+				}
+			}
+
+		case "/label":
+			runLabel(last, parts, p)
+
+		case "/review":
+			limit := defaultHistoryLimit
+			if v := extractFlagValue(parts, "-n"); v != "" {
+				limit, _ = strconv.Atoi(v)
+			}
+			history, err := sharedHistory()
+			if err != nil {
+				fmt.Println(termenv.String(fmt.Sprintf("  ❌ История недоступна: %v", err)).Foreground(colorError))
+				continue
+			}
+			labeled, err := runCalibrationReview(history, limit, scanner, p)
+			if err != nil {
+				fmt.Println(termenv.String(fmt.Sprintf("  ❌ %v", err)).Foreground(colorError))
+			}
+			fmt.Println(termenv.String(fmt.Sprintf("  ✅ Оценено вердиктов: %d. Пересчитать калибровку: calibrate fit", labeled)).Foreground(colorDim))
+
+		case "/stats":
+			days := defaultStatsDays
+			if v := extractFlagValue(parts, "-days"); v != "" {
+				days, _ = strconv.Atoi(v)
+			}
+			if err := runStats(days, extractFlagValue(parts, "-model"), extractFlagValue(parts, "-csv"), p); err != nil {
+				fmt.Println(termenv.String(fmt.Sprintf("  ❌ %v", err)).Foreground(colorError))
+			}
+
 		case "/exit", "/quit":
 			fmt.Println(termenv.String("\n  До свидания! 👋\n").Foreground(colorDim))
 			os.Exit(0)
@@ -115,6 +237,70 @@ func main() {
 	}
 }
 
+// pythonOutput получает сообщения о запуске Python API и его вывод.
+// В неинтерактивном режиме это stderr, чтобы stdout оставался отчётом.
+var pythonOutput io.Writer = os.Stderr
+
+var (
+	pythonOnce sync.Once
+	stopPython = func() {}
+)
+
+// ensurePythonAPI запускает Python API при первой проверке, которой нужен
+// экстрактор; повторные вызовы ничего не делают
+func ensurePythonAPI() {
+	pythonOnce.Do(func() { stopPython = startPythonAPI(pythonOutput) })
+}
+
+// stopPythonAPI останавливает Python API, если его запустила эта программа
+func stopPythonAPI() {
+	stopPython()
+}
+
+// startPythonAPI поднимает Python API, если он ещё не запущен,
+// и возвращает функцию его остановки. Сообщения и вывод Python идут в out.
+func startPythonAPI(out io.Writer) func() {
+	client := NewPythonClient("http://localhost:8000")
+	if client.HealthCheck() != nil {
+		fmt.Fprintln(out, "  🐍 Запуск Python API...")
+
+		workDir, _ := os.Getwd()
+		pythonScript := filepath.Join(workDir, "Python", "app.py")
+
+		cmd := exec.Command("python", pythonScript)
+		cmd.Stdout = out
+		cmd.Stderr = os.Stderr
+		cmd.SysProcAttr = &syscall.SysProcAttr{
+			CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP,
+		}
+
+		if err := cmd.Start(); err != nil {
+			fmt.Fprintln(out, "  ❌ Не удалось запустить Python:", err)
+			fmt.Fprintln(out, "  💡 Запустите вручную: cd Python && python app.py")
+		} else {
+			started := false
+			for i := 0; i < 60; i++ {
+				time.Sleep(1 * time.Second)
+				if client.HealthCheck() == nil {
+					started = true
+					break
+				}
+			}
+
+			if started {
+				fmt.Fprintln(out, "  ✅ Python API готов!")
+				return func() { cmd.Process.Kill() }
+			}
+
+			fmt.Fprintln(out, "  ❌ Python API не запустился за 60 секунд")
+			fmt.Fprintln(out, "  💡 Запустите вручную: cd Python && python app.py")
+			cmd.Process.Kill()
+		}
+	}
+
+	return func() {}
+}
+
 func splitArgs(input string) []string {
 	var parts []string
 	var current strings.Builder
@@ -153,6 +339,24 @@ func extractFlag(parts []string, flag string) string {
 	return ""
 }
 
+// extractFlagValue возвращает значение флага до следующего флага вида -x
+func extractFlagValue(parts []string, flag string) string {
+	for i, part := range parts {
+		if part != flag {
+			continue
+		}
+		var value []string
+		for _, next := range parts[i+1:] {
+			if len(next) == 2 && next[0] == '-' {
+				break
+			}
+			value = append(value, next)
+		}
+		return strings.Join(value, " ")
+	}
+	return ""
+}
+
 func printHelp(p termenv.Profile) {
 	colorCmd := p.Color("#00BFFF")
 	colorFlag := p.Color("#79C0FF")
@@ -165,11 +369,80 @@ func printHelp(p termenv.Profile) {
 	fmt.Println(termenv.String("  ══════════════════════════════════════════").Foreground(colorDim))
 	fmt.Println()
 	fmt.Print(termenv.String("  /check").Foreground(colorCmd))
+	fmt.Print(termenv.String(" [-q").Foreground(colorFlag))
+	fmt.Print(termenv.String(" \"<вопрос>\"]").Foreground(colorDim))
 	fmt.Print(termenv.String(" -r").Foreground(colorFlag))
 	fmt.Println(termenv.String(" \"<ответ ИИ>\"").Foreground(colorDim))
 	fmt.Println(termenv.String("      Полный пайплайн: извлечь утверждения и проверить факты").Foreground(colorDesc))
 	fmt.Println(termenv.String("      Объяснения автоматически переводятся на русский").Foreground(colorDim))
 	fmt.Println(termenv.String("      Пример: /check -r \"Куликовская битва была в 1480 году\"").Foreground(colorDim))
+	fmt.Println(termenv.String("      Go-код в блоках ```go проверяется на выдуманные пакеты и функции").Foreground(colorDim))
+	fmt.Println(termenv.String("      -q — вопрос пользователя: проверить, отвечает ли на него ответ").Foreground(colorDim))
+	fmt.Println(termenv.String("      -f <файл> — ответ из файла; без -r и -f — многострочная вставка до /end").Foreground(colorDim))
+	fmt.Println(termenv.String("      Разметка Markdown и HTML перед извлечением убирается").Foreground(colorDim))
+	fmt.Println()
+	fmt.Print(termenv.String("  /explain").Foreground(colorCmd))
+	fmt.Println(termenv.String(" <N>").Foreground(colorDim))
+	fmt.Println(termenv.String("      Все источники последней проверки для утверждения N").Foreground(colorDesc))
+	fmt.Println()
+	fmt.Print(termenv.String("  /correct").Foreground(colorCmd))
+	fmt.Println(termenv.String(" [-llm]").Foreground(colorFlag))
+	fmt.Println(termenv.String("      Предложить исправленный ответ по опровергающим цитатам").Foreground(colorDesc))
+	fmt.Println(termenv.String("      -llm — переписать через Gemini, иначе офлайн-шаблон").Foreground(colorDim))
+	fmt.Println()
+	fmt.Print(termenv.String("  /export").Foreground(colorCmd))
+	fmt.Println(termenv.String(" <файл.json|файл.md|файл.html>").Foreground(colorDim))
+	fmt.Println(termenv.String("      Сохранить отчёт последней проверки").Foreground(colorDesc))
+	fmt.Println()
+	fmt.Print(termenv.String("  /transcript").Foreground(colorCmd))
+	fmt.Print(termenv.String(" <файл>").Foreground(colorDim))
+	fmt.Print(termenv.String(" [-o").Foreground(colorFlag))
+	fmt.Println(termenv.String(" <отчёт.json|отчёт.md>]").Foreground(colorDim))
+	fmt.Println(termenv.String("      Проверить каждый ответ ассистента в логе диалога").Foreground(colorDesc))
+	fmt.Println(termenv.String("      Форматы: OpenAI messages, блоки content как у Anthropic, JSONL").Foreground(colorDim))
+	fmt.Println()
+	fmt.Print(termenv.String("  /history").Foreground(colorCmd))
+	fmt.Println(termenv.String(" [текст]").Foreground(colorDim))
+	fmt.Println(termenv.String("      Последние проверки или полнотекстовый поиск по ответам и утверждениям").Foreground(colorDesc))
+	fmt.Print(termenv.String("  /show").Foreground(colorCmd))
+	fmt.Print(termenv.String(" <N>").Foreground(colorDim))
+	fmt.Print(termenv.String(", /recheck").Foreground(colorCmd))
+	fmt.Println(termenv.String(" <N>").Foreground(colorDim))
+	fmt.Println(termenv.String("      Открыть проверку из истории или проверить тот же ответ заново").Foreground(colorDesc))
+	fmt.Println(termenv.String("      История хранится в data/history.db").Foreground(colorDim))
+	fmt.Println()
+	fmt.Print(termenv.String("  /stats").Foreground(colorCmd))
+	fmt.Print(termenv.String(" [-days").Foreground(colorFlag))
+	fmt.Print(termenv.String(" 30]").Foreground(colorDim))
+	fmt.Print(termenv.String(" [-model").Foreground(colorFlag))
+	fmt.Print(termenv.String(" <метка>]").Foreground(colorDim))
+	fmt.Print(termenv.String(" [-csv").Foreground(colorFlag))
+	fmt.Println(termenv.String(" <файл>]").Foreground(colorDim))
+	fmt.Println(termenv.String("      Доля галлюцинаций по дням, моделям, типам и темам; частые ошибки").Foreground(colorDesc))
+	fmt.Print(termenv.String("  /model").Foreground(colorCmd))
+	fmt.Println(termenv.String(" <метка|off>").Foreground(colorDim))
+	fmt.Println(termenv.String("      Пометить следующие проверки моделью, давшей ответ").Foreground(colorDesc))
+	fmt.Println()
+	fmt.Print(termenv.String("  /label").Foreground(colorCmd))
+	fmt.Println(termenv.String(" N верно|неверно").Foreground(colorDim))
+	fmt.Println(termenv.String("      Оценить вердикт утверждения последней проверки - для калибровки").Foreground(colorDesc))
+	fmt.Print(termenv.String("  /review").Foreground(colorCmd))
+	fmt.Print(termenv.String(" [-n").Foreground(colorFlag))
+	fmt.Println(termenv.String(" 20]").Foreground(colorDim))
+	fmt.Println(termenv.String("      Оценить по очереди вердикты из истории, которые ещё не оценены").Foreground(colorDesc))
+	fmt.Println()
+	fmt.Print(termenv.String("  /myth").Foreground(colorCmd))
+	fmt.Print(termenv.String(" add -s").Foreground(colorFlag))
+	fmt.Print(termenv.String(" \"<миф>\"").Foreground(colorDim))
+	fmt.Print(termenv.String(" -c").Foreground(colorFlag))
+	fmt.Println(termenv.String(" \"<поправка>\" [-u <источник>]").Foreground(colorDim))
+	fmt.Println(termenv.String("      Добавить известное заблуждение в локальную базу").Foreground(colorDesc))
+	fmt.Println(termenv.String("      /myth без аргументов — показать базу").Foreground(colorDim))
+	fmt.Println()
+	fmt.Print(termenv.String("  /asof").Foreground(colorCmd))
+	fmt.Println(termenv.String(" <YYYY[-MM[-DD]]|off>").Foreground(colorDim))
+	fmt.Println(termenv.String("      Дата, на которую проверять зависящие от времени утверждения").Foreground(colorDesc))
+	fmt.Println(termenv.String("      По умолчанию берётся из ответа (\"по состоянию на 2023\")").Foreground(colorDim))
 	fmt.Println()
 	fmt.Println(termenv.String("  /verify").Foreground(colorCmd))
 	fmt.Println(termenv.String("      Проверить готовность: API ключи и Python сервер").Foreground(colorDesc))
@@ -184,6 +457,27 @@ func printHelp(p termenv.Profile) {
 	fmt.Println(termenv.String("  Переменные окружения:").Foreground(colorDim))
 	fmt.Println(termenv.String("    GEMINI_API_KEY  — для извлечения утверждений").Foreground(colorDim))
 	fmt.Println(termenv.String("    JINA_API_KEY    — для проверки фактов").Foreground(colorDim))
+	fmt.Println(termenv.String("  Без REPL: check [-q \"<вопрос>\"] -r \"<ответ>\"|-f <файл> [-policy файл] [-o отчёт]").Foreground(colorDim))
+	fmt.Println(termenv.String("    код выхода 1, если политика не пройдена").Foreground(colorDim))
+	fmt.Println(termenv.String("    ответ можно передать через stdin: main < answer.md").Foreground(colorDim))
+	fmt.Println(termenv.String("  Без REPL: transcript <файл> [-policy файл] [-o отчёт]").Foreground(colorDim))
+	fmt.Println(termenv.String("  Поток модели: stream [-q \"<вопрос>\"] [-f файл] [-policy файл] [-o отчёт]").Foreground(colorDim))
+	fmt.Println(termenv.String("    SSE chat/completions из stdin проверяется по мере готовности предложений").Foreground(colorDim))
+	fmt.Println(termenv.String("  Сравнение моделей: compare -q \"<вопрос>\" метка=файл ... | -f ответы.jsonl [-o отчёт]").Foreground(colorDim))
+	fmt.Println(termenv.String("    рейтинг ответов, таблица вердиктов по моделям и утверждения, где модели расходятся").Foreground(colorDim))
+	fmt.Println(termenv.String("  Оценка: eval набор.jsonl [-verifiers myths,jina] [-thresholds файл] [-offline] [-name метка]").Foreground(colorDim))
+	fmt.Println(termenv.String("    P/R/F1, матрица ошибок и калибровка по эталонным вердиктам; ответы Jina кэшируются").Foreground(colorDim))
+	fmt.Println(termenv.String("    eval runs — сохранённые прогоны, eval diff 3 5 — сравнить два прогона").Foreground(colorDim))
+	fmt.Println(termenv.String("  Калибровка: calibrate review | label <запись> <N> верно|неверно | fit [-method auto] | show").Foreground(colorDim))
+	fmt.Println(termenv.String("    уверенность проверщиков подгоняется под оценки рецензентов (isotonic или Platt)").Foreground(colorDim))
+	fmt.Println(termenv.String("  Статистика: stats [-days 30] [-model метка] [-csv файл|-]").Foreground(colorDim))
+	fmt.Println(termenv.String("  REST API: serve [-addr :8080] [-policy файл] [-workers 2]").Foreground(colorDim))
+	fmt.Println(termenv.String("    POST /api/analyses {query, response, as_of}, GET /api/analyses[/{id}]").Foreground(colorDim))
+	fmt.Println(termenv.String("    POST /api/analyses/stream — то же, с ходом проверки в Server-Sent Events").Foreground(colorDim))
+	fmt.Println(termenv.String("    POST /api/jobs {items: [...]} — пакет в фоне; GET /api/jobs/{id}, /cancel, /retry").Foreground(colorDim))
+	fmt.Println(termenv.String("  Прокси OpenAI: proxy -upstream <URL> [-addr :8081] [-policy файл] [-block]").Foreground(colorDim))
+	fmt.Println(termenv.String("    ответ /v1/chat/completions дополняется полем hallucination_check").Foreground(colorDim))
+	fmt.Println(termenv.String("    при stream: true проверка идёт параллельно и приходит чанком перед [DONE]").Foreground(colorDim))
 	fmt.Println(termenv.String("  ══════════════════════════════════════════").Foreground(colorDim))
 }
 
@@ -220,7 +514,141 @@ func runVerify(p termenv.Profile) {
 	}
 }
 
-func runFull(response string, p termenv.Profile) {
+// checkOptions - настройки сессии REPL, применяемые к каждой проверке
+type checkOptions struct {
+	ReferenceDate string
+	PolicyFile    string
+	Policy        *Policy // уже загруженная политика; если задана, PolicyFile не читается
+	Model         string  // метка модели, давшей ответ, для статистики
+}
+
+// runModel - /model <метка|off>: какой моделью даны проверяемые ответы
+func runModel(parts []string, opts *checkOptions, p termenv.Profile) {
+	colorOk := p.Color("#3FB950")
+	colorDim := p.Color("#8B949E")
+
+	switch {
+	case len(parts) < 2 && opts.Model == "":
+		fmt.Println(termenv.String("  Метка модели не задана").Foreground(colorDim))
+	case len(parts) < 2:
+		fmt.Println(termenv.String(fmt.Sprintf("  🏷  Модель: %s", opts.Model)).Foreground(colorDim))
+	case parts[1] == "off":
+		opts.Model = ""
+		fmt.Println(termenv.String("  ✅ Метка модели сброшена").Foreground(colorOk))
+	default:
+		opts.Model = parts[1]
+		fmt.Println(termenv.String(fmt.Sprintf("  ✅ Ответы помечаются моделью %s", opts.Model)).Foreground(colorOk))
+	}
+}
+
+func runAsOf(parts []string, opts *checkOptions, p termenv.Profile) {
+	colorErr := p.Color("#FF6B6B")
+	colorOk := p.Color("#3FB950")
+	colorDim := p.Color("#8B949E")
+
+	if len(parts) < 2 {
+		if opts.ReferenceDate == "" {
+			fmt.Println(termenv.String("  Дата знаний берётся из текста ответа (по состоянию на ...)").Foreground(colorDim))
+		} else {
+			fmt.Println(termenv.String(fmt.Sprintf("  📅 Дата проверки: %s", opts.ReferenceDate)).Foreground(colorDim))
+		}
+		return
+	}
+
+	if parts[1] == "off" {
+		opts.ReferenceDate = ""
+		fmt.Println(termenv.String("  ✅ Дата проверки сброшена").Foreground(colorOk))
+		return
+	}
+
+	date, err := parseReferenceDate(parts[1])
+	if err != nil {
+		fmt.Println(termenv.String(fmt.Sprintf("  ❌ %v", err)).Foreground(colorErr))
+		return
+	}
+	opts.ReferenceDate = date
+	fmt.Println(termenv.String(fmt.Sprintf("  ✅ Дата проверки: %s", date)).Foreground(colorOk))
+}
+
+func runCorrect(analysis *AnalysisResult, useLLM bool, p termenv.Profile) *CorrectedResponse {
+	colorErr := p.Color("#FF6B6B")
+	colorWarn := p.Color("#D29922")
+
+	if analysis.Summary.Refuted == 0 {
+		fmt.Println(termenv.String("  ✅ Опровергнутых утверждений нет — исправлять нечего").Foreground(p.Color("#3FB950")))
+		return nil
+	}
+
+	correction := CorrectTemplate(analysis)
+	if useLLM {
+		apiKey := os.Getenv("GEMINI_API_KEY")
+		if apiKey == "" {
+			fmt.Println(termenv.String("  ❌ GEMINI_API_KEY не установлен, используется шаблонный режим").Foreground(colorWarn))
+		} else {
+			fmt.Println("  ✍️  Переписывание через Gemini...")
+			rewritten, err := NewGeminiRewriter(apiKey).CorrectLLM(analysis)
+			if err != nil {
+				fmt.Println(termenv.String(fmt.Sprintf("  ❌ Ошибка Gemini: %v — используется шаблонный режим", err)).Foreground(colorErr))
+			} else {
+				correction = rewritten
+			}
+		}
+	}
+
+	if len(correction.Edits) == 0 {
+		fmt.Println(termenv.String("  ⚠️  Для опровергнутых утверждений не найдено места в ответе или доказательств").Foreground(colorWarn))
+		return nil
+	}
+
+	printCorrection(analysis.Response, correction)
+	return correction
+}
+
+func runMyth(parts []string, p termenv.Profile) {
+	colorErr := p.Color("#FF6B6B")
+	colorOk := p.Color("#3FB950")
+	colorDim := p.Color("#8B949E")
+
+	db, err := LoadMisconceptionDB(misconceptionsFile)
+	if err != nil {
+		fmt.Println(termenv.String(fmt.Sprintf("  ❌ %v", err)).Foreground(colorErr))
+		return
+	}
+
+	if len(parts) < 2 || parts[1] != "add" {
+		if len(db.Entries) == 0 {
+			fmt.Println(termenv.String("  База заблуждений пуста").Foreground(colorDim))
+			return
+		}
+		for i, entry := range db.Entries {
+			fmt.Printf("\n  [%d] %s\n", i+1, entry.Statement)
+			fmt.Println(termenv.String("      ✔ " + entry.Correction).Foreground(colorOk))
+			if entry.Source != "" {
+				fmt.Println(termenv.String("      🔗 " + entry.Source).Foreground(colorDim))
+			}
+		}
+		return
+	}
+
+	entry := Misconception{
+		Statement:  extractFlagValue(parts, "-s"),
+		Correction: extractFlagValue(parts, "-c"),
+		Source:     extractFlagValue(parts, "-u"),
+	}
+	if err := db.Add(entry); err != nil {
+		fmt.Println(termenv.String(fmt.Sprintf("  ❌ %v", err)).Foreground(colorErr))
+		fmt.Println(termenv.String("  💡 /myth add -s \"миф\" -c \"поправка\"").Foreground(colorDim))
+		return
+	}
+	fmt.Println(termenv.String(fmt.Sprintf("  ✅ Добавлено в %s (записей: %d)", misconceptionsFile, len(db.Entries))).Foreground(colorOk))
+}
+
+// errCheckFailed - проверка не выполнена; причина уже показана пользователю
+var errCheckFailed = errors.New("проверка не выполнена")
+
+// runFull проверяет ответ с выводом хода проверки. Ответ без утверждений
+// возвращает errNoClaims, остальные сбои - errCheckFailed.
+func runFull(query, response string, opts checkOptions, p termenv.Profile) (*AnalysisResult, error) {
 	colorErr := p.Color("#FF6B6B")
 	colorOk := p.Color("#3FB950")
 	colorWarn := p.Color("#D29922")
@@ -228,58 +656,43 @@ func runFull(response string, p termenv.Profile) {
 	if os.Getenv("GEMINI_API_KEY") == "" {
 		fmt.Println(termenv.String("  ❌ GEMINI_API_KEY не установлен").Foreground(colorErr))
 		fmt.Println(termenv.String("  💡 https://aistudio.google.com/app/apikey").Foreground(colorWarn))
-		return
+		return nil, errCheckFailed
 	}
 
 	jinaKey := os.Getenv("JINA_API_KEY")
 	if jinaKey == "" {
 		fmt.Println(termenv.String("  ❌ JINA_API_KEY не установлен").Foreground(colorErr))
 		fmt.Println(termenv.String("  💡 https://jina.ai/").Foreground(colorWarn))
-		return
+		return nil, errCheckFailed
 	}
 
+	ensurePythonAPI()
 	client := NewPythonClient("http://localhost:8000")
 	fmt.Println("  🔍 Проверка Python API...")
 	if err := client.HealthCheck(); err != nil {
 		fmt.Println(termenv.String(fmt.Sprintf("  ❌ Python API недоступен: %v", err)).Foreground(colorErr))
 		fmt.Println(termenv.String("  💡 cd Python && python app.py").Foreground(colorWarn))
-		return
+		return nil, errCheckFailed
 	}
 	fmt.Println(termenv.String("  ✅ Python API работает!").Foreground(colorOk))
 
-	fmt.Println("\n  📝 Извлечение утверждений...")
-	result, err := client.ExtractAndSave("", response)
-	if err != nil {
-		fmt.Println(termenv.String(fmt.Sprintf("  ❌ Ошибка извлечения: %v", err)).Foreground(colorErr))
-		return
-	}
-	fmt.Println(termenv.String(fmt.Sprintf("  ✅ Сохранено в: %s", result.Filename)).Foreground(colorOk))
-	fmt.Printf("     Извлечено утверждений: %d\n\n", result.ClaimsCount)
-
-	if result.ClaimsCount == 0 {
+	analysis, err := attachHistory(NewAnalyzer(client, jinaKey)).Analyze(query, response, opts, printProgress)
+	if errors.Is(err, errNoClaims) {
 		fmt.Println(termenv.String("  ⚠️  Утверждений не найдено").Foreground(colorWarn))
-		return
+		return nil, errNoClaims
 	}
-
-	data, err := os.ReadFile(result.Filename)
 	if err != nil {
-		fmt.Println(termenv.String(fmt.Sprintf("  ❌ Не удалось прочитать файл: %v", err)).Foreground(colorErr))
-		return
+		fmt.Println(termenv.String(fmt.Sprintf("  ❌ %v", err)).Foreground(colorErr))
+		return nil, errCheckFailed
 	}
 
-	var claimsData ClaimsData
-	if err := json.Unmarshal(data, &claimsData); err != nil {
-		fmt.Println(termenv.String(fmt.Sprintf("  ❌ Ошибка парсинга JSON: %v", err)).Foreground(colorErr))
-		return
-	}
+	printResults(analysis)
 
-	fmt.Println("  🔎 Проверка через Jina AI Grounding API...")
-	api := NewJinaClient(jinaKey)
-	results, err := api.CheckClaims(claimsData.Claims)
-	if err != nil {
-		fmt.Println(termenv.String(fmt.Sprintf("  ❌ Ошибка проверки: %v", err)).Foreground(colorErr))
-		return
-	}
+	return analysis, nil
+}
 
-	printResults(claimsData, results)
+// noClaimsAnalysis - итог для ответа, в котором нечего проверять: это не сбой,
+// ответ остаётся в отчётах и рейтинге с нулевой сводкой
+func noClaimsAnalysis(query, response string, opts checkOptions) *AnalysisResult {
+	return &AnalysisResult{Query: query, Response: response, Model: opts.Model, CheckedAt: time.Now()}
 }
@@ -0,0 +1,244 @@
+// Go/misconceptions.go
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+const (
+	misconceptionsFile = "data/misconceptions.json"
+
+	// misconceptionThreshold - минимальное сходство токенов (коэффициент Дайса)
+	misconceptionThreshold = 0.6
+
+	// mythReasonPrefix - начало Reason у результатов из базы заблуждений
+	mythReasonPrefix = "Известное заблуждение. "
+
+	// stemLength - грубый стемминг: сравниваем только начало слова,
+	// чтобы "битва/битвы/битве" считались одним токеном
+	stemLength = 5
+)
+
+// Misconception - известное ложное утверждение и его опровержение
+type Misconception struct {
+	Statement  string `json:"statement"`
+	Correction string `json:"correction"`
+	Source     string `json:"source,omitempty"`
+}
+
+// MisconceptionDB - локальная база распространённых заблуждений.
+// Утверждения сверяются с ней до обращения к внешним проверщикам.
+type MisconceptionDB struct {
+	path    string
+	Entries []Misconception
+}
+
+var (
+	numberPattern = regexp.MustCompile(`\d+(?:[.,]\d+)*`)
+
+	stopWords = map[string]bool{
+		"в": true, "во": true, "на": true, "и": true, "с": true, "со": true, "по": true,
+		"из": true, "к": true, "о": true, "об": true, "от": true, "до": true, "за": true,
+		"что": true, "это": true, "был": true, "была": true, "было": true, "были": true,
+		"является": true, "году": true, "год": true, "года": true,
+		"только": true, "лишь": true, "свой": true, "своего": true, "своей": true, "своих": true,
+		"the": true, "a": true, "an": true, "is": true, "are": true, "was": true, "were": true,
+		"of": true, "in": true, "on": true, "at": true, "to": true, "from": true, "and": true,
+		"it": true, "its": true, "be": true, "by": true, "year": true, "only": true, "their": true,
+	}
+
+	// negationWords - отрицания: "стена не видна из космоса" опровергает миф, а не повторяет его
+	negationWords = map[string]bool{
+		"не": true, "нет": true, "ни": true, "никогда": true,
+		"not": true, "no": true, "never": true, "cannot": true,
+	}
+)
+
+// LoadMisconceptionDB читает базу из JSON файла; отсутствующий файл - пустая база
+func LoadMisconceptionDB(path string) (*MisconceptionDB, error) {
+	db := &MisconceptionDB{path: path}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return db, nil
+	}
+	if err != nil {
+		return db, fmt.Errorf("не удалось прочитать %s: %w", path, err)
+	}
+
+	if err := json.Unmarshal(data, &db.Entries); err != nil {
+		return db, fmt.Errorf("ошибка парсинга %s: %w", path, err)
+	}
+
+	return db, nil
+}
+
+// Add добавляет запись и сохраняет базу на диск
+func (db *MisconceptionDB) Add(entry Misconception) error {
+	entry.Statement = strings.TrimSpace(entry.Statement)
+	entry.Correction = strings.TrimSpace(entry.Correction)
+	if entry.Statement == "" || entry.Correction == "" {
+		return fmt.Errorf("нужны и утверждение, и поправка")
+	}
+
+	db.Entries = append(db.Entries, entry)
+	return db.Save()
+}
+
+// Save записывает базу в JSON файл
+func (db *MisconceptionDB) Save() error {
+	if err := os.MkdirAll(filepath.Dir(db.path), 0o755); err != nil {
+		return fmt.Errorf("не удалось создать папку: %w", err)
+	}
+
+	data, err := json.MarshalIndent(db.Entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("ошибка сериализации: %w", err)
+	}
+
+	return os.WriteFile(db.path, data, 0o644)
+}
+
+// Match ищет наиболее похожее заблуждение. Числа в утверждении и записи
+// должны совпадать полностью: "битва в 1380 году" не совпадёт с мифом про 1480.
+// Не совпадает и утверждение с другой полярностью - опровержение мифа.
+func (db *MisconceptionDB) Match(claim string) (*Misconception, float64) {
+	claimWords, claimNumbers := normalizeClaim(claim)
+	if len(claimWords) == 0 {
+		return nil, 0
+	}
+	claimNegated := isNegated(claim)
+
+	var best *Misconception
+	bestScore := 0.0
+
+	for i := range db.Entries {
+		words, numbers := normalizeClaim(db.Entries[i].Statement)
+		if !sameSet(numbers, claimNumbers) || isNegated(db.Entries[i].Statement) != claimNegated {
+			continue
+		}
+
+		score := diceCoefficient(words, claimWords)
+		if score >= misconceptionThreshold && score > bestScore {
+			best = &db.Entries[i]
+			bestScore = score
+		}
+	}
+
+	return best, bestScore
+}
+
+// CheckClaim возвращает готовый результат, если утверждение - известный миф
+func (db *MisconceptionDB) CheckClaim(claim string) (FactCheckResult, bool) {
+	entry, score := db.Match(claim)
+	if entry == nil {
+		return FactCheckResult{}, false
+	}
+
+	return FactCheckResult{
+		Claim:       claim,
+		Found:       true,
+		Result:      false,
+		Factuality:  0,
+		Reason:      mythReasonPrefix + entry.Correction,
+		ReviewURL:   entry.Source,
+		Confidence:  score,
+		Verifier:    "misconceptions",
+		Verdict:     VerdictRefuted,
+		MatchedMyth: entry.Statement,
+	}, true
+}
+
+// Partition сразу помечает известные мифы. Возвращает результаты по всем
+// утверждениям (заполнены только совпавшие) и индексы тех, что нужно
+// отправить внешнему проверщику.
+func (db *MisconceptionDB) Partition(claims []string) ([]FactCheckResult, []int) {
+	results := make([]FactCheckResult, len(claims))
+	var pending []int
+
+	for i, claim := range claims {
+		if r, ok := db.CheckClaim(claim); ok {
+			results[i] = r
+			continue
+		}
+		pending = append(pending, i)
+	}
+
+	return results, pending
+}
+
+// normalizeClaim разбивает текст на нормализованные словесные токены и числа
+func normalizeClaim(text string) (map[string]bool, map[string]bool) {
+	text = strings.ToLower(text)
+	text = strings.ReplaceAll(text, "ё", "е")
+
+	numbers := make(map[string]bool)
+	for _, n := range numberPattern.FindAllString(text, -1) {
+		numbers[strings.ReplaceAll(n, ",", ".")] = true
+	}
+
+	words := make(map[string]bool)
+	for _, token := range strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if stopWords[token] || numberPattern.MatchString(token) {
+			continue
+		}
+		runes := []rune(token)
+		if len(runes) > stemLength {
+			runes = runes[:stemLength]
+		}
+		words[string(runes)] = true
+	}
+
+	return words, numbers
+}
+
+// isNegated - есть ли в тексте отрицание: не, нет, ни, not, never, don't и т.п.
+func isNegated(text string) bool {
+	text = strings.ToLower(strings.ReplaceAll(text, "’", "'"))
+	if strings.Contains(text, "n't") {
+		return true
+	}
+	for _, token := range strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r)
+	}) {
+		if negationWords[token] {
+			return true
+		}
+	}
+	return false
+}
+
+func diceCoefficient(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	common := 0
+	for token := range a {
+		if b[token] {
+			common++
+		}
+	}
+	return 2 * float64(common) / float64(len(a)+len(b))
+}
+
+func sameSet(a, b map[string]bool) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k := range a {
+		if !b[k] {
+			return false
+		}
+	}
+	return true
+}
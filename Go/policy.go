@@ -0,0 +1,227 @@
+// Go/policy.go
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+)
+
+const policyFile = "data/policy.json"
+
+// Серьёзность правила политики
+const (
+	SeverityFail = "fail"
+	SeverityWarn = "warn"
+)
+
+// Метрики, которые может проверять правило
+const (
+	MetricCount             = "count"              // число утверждений, подходящих под where
+	MetricRatio             = "ratio"              // их доля от всех утверждений
+	MetricRiskScore         = "risk_score"         // риск ответа 0–100
+	MetricHallucinationRate = "hallucination_rate" // доля опровергнутых среди проверенных
+)
+
+// ClaimFilter отбирает утверждения для метрик count и ratio.
+// Списки работают как "любое из", условия между полями - как "и".
+type ClaimFilter struct {
+	Verdict          []Verdict `json:"verdict,omitempty"`
+	ClaimType        []string  `json:"claim_type,omitempty"`
+	Verifier         []string  `json:"verifier,omitempty"`
+	HasReference     *bool     `json:"has_reference,omitempty"`
+	TimeSensitive    *bool     `json:"time_sensitive,omitempty"`
+	PossiblyOutdated *bool     `json:"possibly_outdated,omitempty"`
+	MaxFactuality    *float64  `json:"max_factuality,omitempty"`
+}
+
+// PolicyRule - правило вида "metric(where) op value"; при срабатывании
+// ответ получает severity и сообщение message
+type PolicyRule struct {
+	Name     string      `json:"name"`
+	Severity string      `json:"severity"`
+	Message  string      `json:"message"`
+	Metric   string      `json:"metric"`
+	Where    ClaimFilter `json:"where,omitempty"`
+	Op       string      `json:"op"`
+	Value    float64     `json:"value"`
+}
+
+// Policy - набор правил проверки ответа
+type Policy struct {
+	Name  string       `json:"name"`
+	Rules []PolicyRule `json:"rules"`
+}
+
+// PolicyViolation - сработавшее правило
+type PolicyViolation struct {
+	Rule     string  `json:"rule"`
+	Severity string  `json:"severity"`
+	Message  string  `json:"message"`
+	Actual   float64 `json:"actual"`
+}
+
+// PolicyReport - итог применения политики: pass, warn или fail
+type PolicyReport struct {
+	Policy     string            `json:"policy"`
+	Status     string            `json:"status"`
+	Violations []PolicyViolation `json:"violations,omitempty"`
+}
+
+// LoadPolicy читает политику; отсутствующий файл - не ошибка, а nil
+func LoadPolicy(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("не удалось прочитать %s: %w", path, err)
+	}
+
+	var policy Policy
+	if err := json.Unmarshal(data, &policy); err != nil {
+		return nil, fmt.Errorf("ошибка парсинга %s: %w", path, err)
+	}
+	if err := policy.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &policy, nil
+}
+
+// LoadRequiredPolicy читает политику, без которой работать нельзя: отсутствующий
+// файл - тоже ошибка
+func LoadRequiredPolicy(path string) (*Policy, error) {
+	policy, err := LoadPolicy(path)
+	if err == nil && policy == nil {
+		err = fmt.Errorf("файл политики %s не найден", path)
+	}
+	return policy, err
+}
+
+func (p *Policy) validate() error {
+	for i, rule := range p.Rules {
+		name := rule.Name
+		if name == "" {
+			name = fmt.Sprintf("#%d", i+1)
+		}
+		switch rule.Severity {
+		case SeverityFail, SeverityWarn:
+		default:
+			return fmt.Errorf("правило %s: severity должен быть fail или warn", name)
+		}
+		switch rule.Metric {
+		case MetricCount, MetricRatio, MetricRiskScore, MetricHallucinationRate:
+		default:
+			return fmt.Errorf("правило %s: неизвестная метрика %q", name, rule.Metric)
+		}
+		if _, err := compare(0, rule.Op, 0); err != nil {
+			return fmt.Errorf("правило %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// Evaluate применяет политику к результату анализа
+func (p *Policy) Evaluate(analysis *AnalysisResult) PolicyReport {
+	report := PolicyReport{Policy: p.Name, Status: "pass"}
+
+	for _, rule := range p.Rules {
+		actual := rule.measure(analysis)
+		triggered, _ := compare(actual, rule.Op, rule.Value)
+		if !triggered {
+			continue
+		}
+
+		report.Violations = append(report.Violations, PolicyViolation{
+			Rule:     rule.Name,
+			Severity: rule.Severity,
+			Message:  rule.Message,
+			Actual:   actual,
+		})
+		if rule.Severity == SeverityFail {
+			report.Status = "fail"
+		} else if report.Status == "pass" {
+			report.Status = "warn"
+		}
+	}
+
+	return report
+}
+
+// ExitCode - код завершения для неинтерактивного запуска
+func (r *PolicyReport) ExitCode() int {
+	if r != nil && r.Status == "fail" {
+		return exitPolicyFail
+	}
+	return exitOK
+}
+
+func (rule PolicyRule) measure(analysis *AnalysisResult) float64 {
+	switch rule.Metric {
+	case MetricRiskScore:
+		return analysis.Risk.Score
+	case MetricHallucinationRate:
+		return analysis.Summary.HallucinationRate
+	}
+
+	count := 0
+	for _, r := range analysis.FactCheckResults {
+		if rule.Where.Match(r) {
+			count++
+		}
+	}
+	if rule.Metric == MetricRatio {
+		if len(analysis.FactCheckResults) == 0 {
+			return 0
+		}
+		return float64(count) / float64(len(analysis.FactCheckResults))
+	}
+	return float64(count)
+}
+
+// Match - подходит ли утверждение под фильтр
+func (f ClaimFilter) Match(r FactCheckResult) bool {
+	if len(f.Verdict) > 0 && !slices.Contains(f.Verdict, r.Verdict) {
+		return false
+	}
+	if len(f.ClaimType) > 0 && !slices.Contains(f.ClaimType, r.ClaimType) {
+		return false
+	}
+	if len(f.Verifier) > 0 && !slices.Contains(f.Verifier, r.Verifier) {
+		return false
+	}
+	if f.HasReference != nil && *f.HasReference != (r.ReviewURL != "" || len(r.References) > 0) {
+		return false
+	}
+	if f.TimeSensitive != nil && *f.TimeSensitive != r.TimeSensitive {
+		return false
+	}
+	if f.PossiblyOutdated != nil && *f.PossiblyOutdated != r.PossiblyOutdated {
+		return false
+	}
+	if f.MaxFactuality != nil && r.Factuality > *f.MaxFactuality {
+		return false
+	}
+	return true
+}
+
+func compare(actual float64, op string, value float64) (bool, error) {
+	switch op {
+	case ">":
+		return actual > value, nil
+	case ">=":
+		return actual >= value, nil
+	case "<":
+		return actual < value, nil
+	case "<=":
+		return actual <= value, nil
+	case "==":
+		return actual == value, nil
+	case "!=":
+		return actual != value, nil
+	}
+	return false, fmt.Errorf("неизвестный оператор %q", op)
+}
@@ -0,0 +1,375 @@
+// Go/proxy.go
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// proxyCheckField - поле, которое прокси добавляет в ответ chat/completions
+const proxyCheckField = "hallucination_check"
+
+// Заголовки, которые не передаются между клиентом, прокси и upstream
+var hopHeaders = map[string]bool{
+	"Connection": true, "Keep-Alive": true, "Proxy-Authenticate": true, "Proxy-Authorization": true,
+	"Te": true, "Trailer": true, "Transfer-Encoding": true, "Upgrade": true,
+	"Content-Length": true, "Host": true, "Accept-Encoding": true,
+}
+
+// ProxyClaim - вердикт по утверждению в ответе прокси
+type ProxyClaim struct {
+	Claim      string          `json:"claim"`
+	Verdict    Verdict         `json:"verdict"`
+	Factuality float64         `json:"factuality"`
+	Confidence float64         `json:"confidence"`
+	ClaimType  string          `json:"claim_type,omitempty"`
+	Source     string          `json:"source,omitempty"`
+	Mismatches []ClaimMismatch `json:"mismatches,omitempty"`
+}
+
+// ProxyCheck - результат проверки, добавляемый к ответу модели
+type ProxyCheck struct {
+	Claims  []ProxyClaim   `json:"claims"`
+	Summary *ResultSummary `json:"summary,omitempty"`
+	Risk    *RiskScore     `json:"risk,omitempty"`
+	Policy  *PolicyReport  `json:"policy,omitempty"`
+	Error   string         `json:"error,omitempty"`
+}
+
+// newProxyCheck сворачивает AnalysisResult в компактный вид для клиента
+func newProxyCheck(analysis *AnalysisResult) *ProxyCheck {
+	check := &ProxyCheck{
+		Claims:  make([]ProxyClaim, 0, len(analysis.FactCheckResults)),
+		Summary: &analysis.Summary,
+		Risk:    &analysis.Risk,
+		Policy:  analysis.Policy,
+	}
+	for _, r := range analysis.FactCheckResults {
+		check.Claims = append(check.Claims, ProxyClaim{
+			Claim:      r.Claim,
+			Verdict:    r.Verdict,
+			Factuality: r.Factuality,
+			Confidence: r.Confidence,
+			ClaimType:  r.ClaimType,
+			Source:     r.ReviewURL,
+			Mismatches: r.Mismatches,
+		})
+	}
+	return check
+}
+
+// Proxy - OpenAI-совместимый прокси: пересылает /v1/chat/completions
+// в upstream и проверяет ответ ассистента. В режиме blocking ответы,
+// не прошедшие политику, заменяются ошибкой.
+type Proxy struct {
+	upstream   *url.URL
+	analyzer   *Analyzer
+	policy     *Policy
+	blocking   bool
+	httpClient *http.Client
+}
+
+// NewProxy создает прокси; upstream - базовый URL провайдера,
+// например https://api.openai.com или http://localhost:9000. Политика
+// загружается один раз: испорченный файл, а в блокирующем режиме и
+// отсутствующий, не дают прокси запуститься.
+func NewProxy(upstream string, analyzer *Analyzer, policyFile string, blocking bool) (*Proxy, error) {
+	u, err := url.Parse(strings.TrimRight(upstream, "/"))
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("неверный адрес upstream %q", upstream)
+	}
+	if blocking && policyFile == "" {
+		return nil, fmt.Errorf("для блокирующего режима нужна политика")
+	}
+
+	var policy *Policy
+	if policyFile != "" {
+		load := LoadPolicy
+		if blocking {
+			load = LoadRequiredPolicy
+		}
+		if policy, err = load(policyFile); err != nil {
+			return nil, fmt.Errorf("политика не загружена: %w", err)
+		}
+	}
+	return &Proxy{
+		upstream:   u,
+		analyzer:   analyzer,
+		policy:     policy,
+		blocking:   blocking,
+		httpClient: &http.Client{Timeout: 5 * time.Minute},
+	}, nil
+}
+
+// Handler - маршруты прокси
+func (px *Proxy) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /health", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+	mux.HandleFunc("POST /v1/chat/completions", px.handleChatCompletions)
+	return logRequests(mux)
+}
+
+// ListenAndServe работает до Ctrl+C или SIGTERM
+func (px *Proxy) ListenAndServe(addr string) error {
+	return serveUntilSignal(newHTTPServer(addr, px.Handler()))
+}
+
+func (px *Proxy) handleChatCompletions(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestBody))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeOpenAIError(w, http.StatusRequestEntityTooLarge, "invalid_request_error", fmt.Sprintf("тело запроса больше %d байт", tooLarge.Limit), nil)
+			return
+		}
+		writeOpenAIError(w, http.StatusBadRequest, "invalid_request_error", err.Error(), nil)
+		return
+	}
+
+	var request struct {
+		Model    string       `json:"model"`
+		Messages []rawMessage `json:"messages"`
+		Stream   bool         `json:"stream"`
+	}
+	if err := json.Unmarshal(body, &request); err != nil {
+		writeOpenAIError(w, http.StatusBadRequest, "invalid_request_error", fmt.Sprintf("некорректный JSON: %v", err), nil)
+		return
+	}
+
+	resp, err := px.forward(r, body)
+	if err != nil {
+		writeOpenAIError(w, http.StatusBadGateway, "upstream_error", err.Error(), nil)
+		return
+	}
+	defer resp.Body.Close()
+
+	// Ошибки провайдера отдаются как есть
+	if resp.StatusCode != http.StatusOK {
+		copyHeaders(w.Header(), resp.Header)
+		w.WriteHeader(resp.StatusCode)
+		if err := copyFlushing(w, resp.Body); err != nil {
+			log.Printf("Ошибка передачи ответа upstream: %v", err)
+		}
+		return
+	}
+
+	if request.Stream {
+		px.streamCompletion(w, resp, lastUserMessage(request.Messages), request.Model)
+		return
+	}
+
+	upstreamBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		writeOpenAIError(w, http.StatusBadGateway, "upstream_error", fmt.Sprintf("ошибка чтения ответа upstream: %v", err), nil)
+		return
+	}
+
+	var completion map[string]any
+	var parsed struct {
+		Choices []struct {
+			Message rawMessage `json:"message"`
+		} `json:"choices"`
+	}
+	if err := json.Unmarshal(upstreamBody, &completion); err != nil {
+		writeOpenAIError(w, http.StatusBadGateway, "upstream_error", fmt.Sprintf("upstream вернул не JSON: %v", err), nil)
+		return
+	}
+	json.Unmarshal(upstreamBody, &parsed)
+
+	answer := ""
+	if len(parsed.Choices) > 0 {
+		answer, _ = messageText(parsed.Choices[0].Message.Content)
+	}
+
+	check := px.check(lastUserMessage(request.Messages), answer, request.Model)
+	if px.blocking {
+		switch {
+		case check.Error != "":
+			writeOpenAIError(w, http.StatusBadGateway, "hallucination_check_error", "ответ не проверен: "+check.Error, check)
+			return
+		// Пустой ответ (например, только вызовы инструментов) проверять нечего
+		case check.Policy == nil && strings.TrimSpace(answer) != "":
+			writeOpenAIError(w, http.StatusBadGateway, "hallucination_check_error", "ответ не проверен политикой", check)
+			return
+		case check.Policy != nil && check.Policy.Status == "fail":
+			writeOpenAIError(w, http.StatusUnprocessableEntity, "hallucination_policy_failed",
+				fmt.Sprintf("ответ модели не прошёл политику %s", check.Policy.Policy), check)
+			return
+		}
+	}
+
+	completion[proxyCheckField] = check
+	copyHeaders(w.Header(), resp.Header)
+	if check.Risk != nil {
+		w.Header().Set("X-Hallucination-Risk", fmt.Sprintf("%.0f", check.Risk.Score))
+	}
+	if check.Policy != nil {
+		w.Header().Set("X-Hallucination-Policy", check.Policy.Status)
+	}
+	writeJSON(w, http.StatusOK, completion)
+}
+
+// forward пересылает запрос в upstream с теми же заголовками (включая Authorization)
+func (px *Proxy) forward(r *http.Request, body []byte) (*http.Response, error) {
+	target := *px.upstream
+	target.Path = strings.TrimRight(target.Path, "/") + r.URL.Path
+	target.RawQuery = r.URL.RawQuery
+
+	req, err := http.NewRequestWithContext(r.Context(), http.MethodPost, target.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	copyHeaders(req.Header, r.Header)
+
+	resp, err := px.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("upstream недоступен: %w", err)
+	}
+	return resp, nil
+}
+
+// check проверяет ответ ассистента; ошибка проверки не ломает ответ модели
+func (px *Proxy) check(query, answer, model string) *ProxyCheck {
+	if strings.TrimSpace(answer) == "" {
+		return &ProxyCheck{Claims: []ProxyClaim{}}
+	}
+	return proxyCheckResult(px.analyzer.Analyze(query, answer, checkOptions{Policy: px.policy, Model: model}, nil))
+}
+
+// streamCompletion передаёт поток модели клиенту без задержек и параллельно
+// проверяет его по предложениям; перед [DONE] добавляется чанк без choices
+// с полем hallucination_check. Уже отправленный текст не отменить, поэтому
+// блокирующий режим на потоковые ответы не действует.
+func (px *Proxy) streamCompletion(w http.ResponseWriter, resp *http.Response, query, model string) {
+	copyHeaders(w.Header(), resp.Header)
+	w.WriteHeader(http.StatusOK)
+	rc := http.NewResponseController(w)
+
+	tokens := make(chan string)
+	checked := make(chan *ProxyCheck, 1)
+	go func() {
+		checked <- proxyCheckResult(px.analyzer.AnalyzeStream(query, tokens, checkOptions{Policy: px.policy, Model: model}, nil))
+	}()
+
+	reader := bufio.NewReader(resp.Body)
+	done := false
+	for {
+		line, err := reader.ReadString('\n')
+		text, last := chatStreamDelta(line)
+		if last {
+			done = true
+			break
+		}
+		if line != "" {
+			io.WriteString(w, line)
+			rc.Flush()
+		}
+		if text != "" {
+			tokens <- text
+		}
+		if err != nil {
+			if err != io.EOF {
+				log.Printf("Ошибка передачи ответа upstream: %v", err)
+			}
+			break
+		}
+	}
+	close(tokens)
+
+	chunk, err := json.Marshal(map[string]any{
+		"object":        "chat.completion.chunk",
+		"choices":       []any{},
+		proxyCheckField: <-checked,
+	})
+	if err != nil {
+		log.Printf("Ошибка кодирования проверки: %v", err)
+		return
+	}
+	fmt.Fprintf(w, "data: %s\n\n", chunk)
+	if done {
+		io.WriteString(w, "data: [DONE]\n\n")
+	}
+	rc.Flush()
+}
+
+// proxyCheckResult сворачивает результат пайплайна; «утверждений нет» - не ошибка
+func proxyCheckResult(analysis *AnalysisResult, err error) *ProxyCheck {
+	if errors.Is(err, errNoClaims) {
+		return &ProxyCheck{Claims: []ProxyClaim{}}
+	}
+	if err != nil {
+		log.Printf("Проверка ответа не выполнена: %v", err)
+		return &ProxyCheck{Claims: []ProxyClaim{}, Error: err.Error()}
+	}
+	return newProxyCheck(analysis)
+}
+
+// lastUserMessage - последний вопрос пользователя в запросе
+func lastUserMessage(messages []rawMessage) string {
+	for i := len(messages) - 1; i >= 0; i-- {
+		if normalizeRole(messages[i].Role) == "user" {
+			text, _ := messageText(messages[i].Content)
+			return text
+		}
+	}
+	return ""
+}
+
+// copyFlushing передаёт тело по мере поступления, чтобы SSE-поток не копился в буфере
+func copyFlushing(w http.ResponseWriter, body io.Reader) error {
+	rc := http.NewResponseController(w)
+	buf := make([]byte, 4096)
+	for {
+		n, err := body.Read(buf)
+		if n > 0 {
+			if _, werr := w.Write(buf[:n]); werr != nil {
+				return werr
+			}
+			rc.Flush()
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func copyHeaders(dst, src http.Header) {
+	for name, values := range src {
+		if hopHeaders[http.CanonicalHeaderKey(name)] {
+			continue
+		}
+		dst.Del(name)
+		for _, v := range values {
+			dst.Add(name, v)
+		}
+	}
+}
+
+// writeOpenAIError - ошибка в формате OpenAI API, чтобы клиентские SDK её разобрали
+func writeOpenAIError(w http.ResponseWriter, status int, errType, message string, check *ProxyCheck) {
+	body := map[string]any{
+		"error": map[string]any{
+			"message": message,
+			"type":    errType,
+		},
+	}
+	if check != nil {
+		body[proxyCheckField] = check
+	}
+	writeJSON(w, status, body)
+}
@@ -0,0 +1,303 @@
+// Go/quotediff.go
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Таксономия расхождений утверждения с цитатой источника
+const (
+	MismatchNumber   = "wrong_number"
+	MismatchDate     = "wrong_date"
+	MismatchEntity   = "wrong_entity"
+	MismatchRelation = "wrong_relation"
+)
+
+// minRelationOverlap - насколько цитата должна совпадать по словам с утверждением,
+// чтобы считать, что речь о том же: только тогда сравниваются имена и связи
+const minRelationOverlap = 0.4
+
+// ClaimMismatch - фрагмент утверждения, расходящийся с цитатой
+type ClaimMismatch struct {
+	Kind  string `json:"kind"`
+	Claim string `json:"claim"`
+	Quote string `json:"quote,omitempty"` // значение из цитаты; для wrong_relation может быть пустым
+}
+
+// quoteToken - слово с нормализованной формой для сравнения
+type quoteToken struct {
+	text string
+	norm string
+	kind string // number, date, entity, word
+}
+
+var entityStopWords = map[string]bool{
+	"the": true, "a": true, "an": true, "in": true, "on": true, "it": true, "this": true,
+	"в": true, "на": true, "это": true, "он": true, "она": true, "они": true,
+}
+
+// classifyTokens режет текст на слова и помечает числа, годы и имена собственные
+func classifyTokens(text string) []quoteToken {
+	var tokens []quoteToken
+	for _, w := range tokenizeWords(text) {
+		runes := []rune(w)
+		if len(runes) == 0 || !(unicode.IsLetter(runes[0]) || unicode.IsDigit(runes[0])) {
+			continue
+		}
+
+		t := quoteToken{text: w, kind: "word"}
+		switch {
+		case unicode.IsDigit(runes[0]):
+			t.norm = w
+			t.kind = "number"
+			if len(runes) == 4 && (w[0] == '1' || w[0] == '2') {
+				t.kind = "date"
+			}
+		default:
+			lower := strings.ReplaceAll(strings.ToLower(w), "ё", "е")
+			if len(runes) > stemLength {
+				lower = string([]rune(lower)[:stemLength])
+			}
+			t.norm = lower
+			if unicode.IsUpper(runes[0]) && !entityStopWords[strings.ToLower(w)] {
+				t.kind = "entity"
+			}
+		}
+		tokens = append(tokens, t)
+	}
+	return mergeDecimals(tokens, text)
+}
+
+// mergeDecimals склеивает "12", ".", "5" обратно в "12.5" там, где в тексте было число с дробью
+func mergeDecimals(tokens []quoteToken, text string) []quoteToken {
+	var merged []quoteToken
+	for _, t := range tokens {
+		if n := len(merged); n > 0 && t.kind == "number" && merged[n-1].kind == "number" {
+			for _, sep := range []string{".", ","} {
+				joined := merged[n-1].text + sep + t.text
+				if strings.Contains(text, joined) {
+					merged[n-1].text = joined
+					merged[n-1].norm = merged[n-1].norm + "." + t.text
+					merged[n-1].kind = "number"
+					t.kind = ""
+					break
+				}
+			}
+			if t.kind == "" {
+				continue
+			}
+		}
+		merged = append(merged, t)
+	}
+	return merged
+}
+
+// sameScript - оба текста на одной письменности; имена в русском утверждении
+// и английской цитате сравнивать бессмысленно
+func sameScript(a, b string) bool {
+	return isMostlyCyrillic(a) == isMostlyCyrillic(b)
+}
+
+func isMostlyCyrillic(text string) bool {
+	cyr, lat := 0, 0
+	for _, r := range text {
+		switch {
+		case unicode.Is(unicode.Cyrillic, r):
+			cyr++
+		case unicode.Is(unicode.Latin, r):
+			lat++
+		}
+	}
+	return cyr > lat
+}
+
+// quoteDiff выравнивает значимые слова утверждения и цитаты через diffTokens.
+// Для каждого слова утверждения возвращает номер блока изменений (-1 - слово
+// совпало), для каждого слова цитаты - тот же номер блока, куда оно вставлено.
+func quoteDiff(claimTokens, quoteTokens []quoteToken) (claimBlock, quoteBlock []int) {
+	// Нормальные формы не содержат пробелов, поэтому после склейки соседних
+	// операций в diffTokens их можно снова разделить по пробелу
+	norms := func(tokens []quoteToken) []string {
+		out := make([]string, len(tokens))
+		for i, t := range tokens {
+			out[i] = t.norm + " "
+		}
+		return out
+	}
+
+	claimBlock = make([]int, 0, len(claimTokens))
+	quoteBlock = make([]int, 0, len(quoteTokens))
+	block := 0
+	for _, op := range diffTokens(norms(claimTokens), norms(quoteTokens)) {
+		n := len(strings.Fields(op.Text))
+		for range n {
+			switch op.Kind {
+			case diffEqual:
+				claimBlock = append(claimBlock, -1)
+				quoteBlock = append(quoteBlock, -1)
+			case diffDelete:
+				claimBlock = append(claimBlock, block)
+			case diffInsert:
+				quoteBlock = append(quoteBlock, block)
+			}
+		}
+		if op.Kind == diffEqual {
+			block++
+		}
+	}
+	return claimBlock, quoteBlock
+}
+
+// CompareWithQuote сопоставляет утверждение с цитатой и размечает расхождения.
+// Значение из цитаты подбирается в том же блоке изменений diff (для чисел -
+// и в остальной цитате). Числа сравниваются всегда; имена и связи - только
+// если тексты на одном языке и заметно совпадают по словам.
+// wrong_relation ставится опровергнутым утверждениям, у которых числа и имена
+// совпали с цитатой, а значит неверна сама связь между ними.
+func CompareWithQuote(claim, quote string, refuted bool) []ClaimMismatch {
+	if strings.TrimSpace(quote) == "" {
+		return nil
+	}
+	claimTokens := classifyTokens(claim)
+	quoteTokens := classifyTokens(quote)
+	claimWords, _ := normalizeClaim(claim)
+	quoteWords, _ := normalizeClaim(quote)
+	// Имена и связи сравниваются, только если цитата про то же самое: иначе
+	// "Москва" в утверждении сопоставится с любым заглавным словом цитаты
+	compareNames := sameScript(claim, quote) && overlapRatio(claimWords, quoteWords) >= minRelationOverlap
+	claimBlock, quoteBlock := quoteDiff(claimTokens, quoteTokens)
+
+	inQuote := make(map[string]bool)
+	for _, t := range quoteTokens {
+		inQuote[t.norm] = true
+	}
+	inClaim := make(map[string]bool)
+	for _, t := range claimTokens {
+		inClaim[t.norm] = true
+	}
+
+	// Числа в цитате часто стоят в другом порядке, поэтому для них значение
+	// ищется по всей цитате; имена - только в том же блоке изменений, иначе
+	// заглавное слово в начале предложения сопоставится с чем попало
+	used := make([]bool, len(quoteTokens))
+	counterpart := func(kind string, block int) string {
+		passes := 2
+		if kind != "number" && kind != "date" {
+			passes = 1
+		}
+		for pass := 0; pass < passes; pass++ {
+			for j, t := range quoteTokens {
+				if used[j] || t.kind != kind || inClaim[t.norm] {
+					continue
+				}
+				if pass == 0 && quoteBlock[j] != block {
+					continue
+				}
+				used[j] = true
+				return t.text
+			}
+		}
+		return ""
+	}
+
+	// Расхождение - только если в цитате на этом месте стоит другое значение
+	// того же вида; слово, которого в цитате просто нет, ошибкой не считается
+	var mismatches []ClaimMismatch
+	for i, t := range claimTokens {
+		// Слово есть в цитате, просто в другом месте - это не ошибка
+		if claimBlock[i] < 0 || inQuote[t.norm] {
+			continue
+		}
+		var kind string
+		switch t.kind {
+		case "date":
+			kind = MismatchDate
+		case "number":
+			kind = MismatchNumber
+		case "entity":
+			if !compareNames {
+				continue
+			}
+			kind = MismatchEntity
+		default:
+			continue
+		}
+		if quoted := counterpart(t.kind, claimBlock[i]); quoted != "" {
+			mismatches = append(mismatches, ClaimMismatch{Kind: kind, Claim: t.text, Quote: quoted})
+		}
+	}
+
+	if len(mismatches) == 0 && refuted && compareNames {
+		var differing, replacing []string
+		for i, t := range claimTokens {
+			if t.kind == "word" && claimBlock[i] >= 0 && !inQuote[t.norm] && !stopWords[strings.ToLower(t.text)] {
+				differing = append(differing, t.text)
+				if q := counterpart("word", claimBlock[i]); q != "" {
+					replacing = append(replacing, q)
+				}
+			}
+		}
+		if len(differing) > 0 {
+			mismatches = append(mismatches, ClaimMismatch{
+				Kind:  MismatchRelation,
+				Claim: strings.Join(differing, " "),
+				Quote: strings.Join(replacing, " "),
+			})
+		}
+	}
+
+	return mismatches
+}
+
+// overlapRatio - доля слов утверждения, встречающихся в цитате
+func overlapRatio(claim, quote map[string]bool) float64 {
+	if len(claim) == 0 {
+		return 0
+	}
+	common := 0
+	for w := range claim {
+		if quote[w] {
+			common++
+		}
+	}
+	return float64(common) / float64(len(claim))
+}
+
+// AnalyzeQuoteMismatches сравнивает каждое утверждение с выбранной цитатой источника
+func AnalyzeQuoteMismatches(results []FactCheckResult) {
+	for i := range results {
+		r := &results[i]
+		if r.KeyQuote == "" || r.Verifier == "gocode" {
+			continue
+		}
+		refuted := r.Verdict == VerdictRefuted || r.Verdict == VerdictDisputed
+		r.Mismatches = CompareWithQuote(r.Claim, r.KeyQuote, refuted)
+	}
+}
+
+// mismatchLabel - название вида расхождения для отчётов
+func mismatchLabel(kind string) string {
+	switch kind {
+	case MismatchNumber:
+		return "неверное число"
+	case MismatchDate:
+		return "неверная дата"
+	case MismatchEntity:
+		return "неверное имя"
+	case MismatchRelation:
+		return "неверная связь"
+	}
+	return kind
+}
+
+// mismatchText - расхождение одной строкой: "неверное число: 1480 (в источнике: 1380)"
+func mismatchText(m ClaimMismatch) string {
+	text := fmt.Sprintf("%s: %s", mismatchLabel(m.Kind), m.Claim)
+	if m.Quote != "" {
+		text += fmt.Sprintf(" (в источнике: %s)", m.Quote)
+	}
+	return text
+}
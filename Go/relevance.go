@@ -0,0 +1,140 @@
+// Go/relevance.go
+
+package main
+
+import (
+	"math"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// relevanceStemLength - для сравнения с вопросом основы короче, чем в базе мифов:
+// вопрос и ответ обычно в разных падежах ("битва" - "битве")
+const relevanceStemLength = 4
+
+// minAnswerCoverage - какая доля слов вопроса должна встретиться в ответе,
+// чтобы ответ считался ответом на этот вопрос
+const minAnswerCoverage = 0.5
+
+// Вопросительные слова и тип утверждения, который ожидается в ответе
+var expectedAnswerPatterns = []struct {
+	pattern   *regexp.Regexp
+	claimType string
+}{
+	{regexp.MustCompile(`(?i)^\s*(?:когда|в каком году|в каком веке|какого числа|when|what year|which year)(?:[^\p{L}]|$)`), ClaimTypeDate},
+	{regexp.MustCompile(`(?i)^\s*(?:сколько|how many|how much|how long|how old|how far)(?:[^\p{L}]|$)`), ClaimTypeNumeric},
+	{regexp.MustCompile(`(?i)^\s*(?:кто|кого|где|who|whom|where)(?:[^\p{L}]|$)`), ClaimTypeEntity},
+}
+
+// RelevanceReport - насколько ответ отвечает на вопрос, отдельно от фактической точности.
+// Unrequested - номера утверждений (с 1), не относящихся к вопросу.
+type RelevanceReport struct {
+	Score        float64 `json:"score"`
+	Addressed    bool    `json:"addressed"`
+	ExpectedType string  `json:"expected_type,omitempty"`
+	AnswerFound  bool    `json:"answer_found"`
+	Unrequested  []int   `json:"unrequested,omitempty"`
+}
+
+// expectedAnswerType - тип утверждения, которого ждёт вопрос ("когда" -> дата)
+func expectedAnswerType(query string) string {
+	for _, e := range expectedAnswerPatterns {
+		if e.pattern.MatchString(query) {
+			return e.claimType
+		}
+	}
+	return ""
+}
+
+// Вопросительные слова не встречаются в ответе и не должны снижать покрытие
+var questionWords = map[string]bool{
+	"когда": true, "сколько": true, "кто": true, "кого": true, "где": true, "что": true,
+	"какой": true, "какая": true, "какое": true, "какие": true, "каком": true, "как": true,
+	"почему": true, "зачем": true, "ли": true,
+	"when": true, "what": true, "who": true, "whom": true, "where": true, "which": true,
+	"how": true, "why": true, "many": true, "much": true, "does": true, "did": true,
+}
+
+// relevanceWords - значимые слова текста, укороченные до relevanceStemLength
+func relevanceWords(text string) map[string]bool {
+	text = strings.ReplaceAll(strings.ToLower(text), "ё", "е")
+	stems := make(map[string]bool)
+	for _, token := range strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if stopWords[token] || questionWords[token] || numberPattern.MatchString(token) {
+			continue
+		}
+		if runes := []rune(token); len(runes) > relevanceStemLength {
+			token = string(runes[:relevanceStemLength])
+		}
+		stems[token] = true
+	}
+	return stems
+}
+
+// answersType - подходит ли утверждение под ожидаемый тип ответа;
+// дата - тоже число, поэтому на "сколько лет" годится и она
+func answersType(claimType, expected string) bool {
+	return claimType == expected || (expected == ClaimTypeNumeric && claimType == ClaimTypeDate)
+}
+
+// ScoreRelevance сравнивает ответ и утверждения с вопросом пользователя.
+// Score - доля слов вопроса, встретившихся в ответе. Утверждение относится
+// к вопросу, если делит с ним слово, или делит слово с таким утверждением
+// (ответ на "столица Франции?" может продолжаться "Париж стоит на Сене").
+// Возвращает nil, если вопрос не задан.
+func ScoreRelevance(query, response string, results []FactCheckResult) *RelevanceReport {
+	if strings.TrimSpace(query) == "" {
+		return nil
+	}
+
+	queryWords := relevanceWords(query)
+	responseWords := relevanceWords(response)
+	report := &RelevanceReport{
+		Score:        math.Round(overlapRatio(queryWords, responseWords)*100) / 100,
+		ExpectedType: expectedAnswerType(query),
+	}
+	// Вопрос из одних служебных слов ("Кто это?") не с чем сравнивать
+	if len(queryWords) == 0 {
+		report.Score = 1
+	}
+
+	onTopic := make([]bool, len(results))
+	topic := make(map[string]bool)
+	for i, r := range results {
+		if r.Verifier == "gocode" {
+			onTopic[i] = true
+			continue
+		}
+		words := relevanceWords(r.Claim)
+		if len(queryWords) == 0 || overlapRatio(queryWords, words) > 0 {
+			onTopic[i] = true
+			for w := range words {
+				topic[w] = true
+			}
+		}
+	}
+	for i, r := range results {
+		if onTopic[i] {
+			continue
+		}
+		words := relevanceWords(r.Claim)
+		if overlapRatio(words, topic) > 0 {
+			onTopic[i] = true
+		} else {
+			report.Unrequested = append(report.Unrequested, i+1)
+		}
+	}
+
+	for i, r := range results {
+		if onTopic[i] && (report.ExpectedType == "" || answersType(r.ClaimType, report.ExpectedType)) {
+			report.AnswerFound = true
+			break
+		}
+	}
+
+	report.Addressed = report.Score >= minAnswerCoverage && report.AnswerFound
+	return report
+}
@@ -0,0 +1,157 @@
+// Go/risk.go
+
+package main
+
+import "math"
+
+// RiskFactor - вклад одной группы причин в итоговый риск (в баллах из 100)
+type RiskFactor struct {
+	Name   string  `json:"name"`
+	Points float64 `json:"points"`
+	Count  int     `json:"count"`
+}
+
+// RiskScore - насколько ответу нельзя доверять: 0 - надёжен, 100 - сплошные галлюцинации
+type RiskScore struct {
+	Score   float64      `json:"score"`
+	Level   string       `json:"level"`
+	Factors []RiskFactor `json:"factors"`
+}
+
+// Риск утверждения в зависимости от вердикта
+var verdictRisk = map[Verdict]float64{
+	VerdictSupported:    0,
+	VerdictRefuted:      1,
+	VerdictDisputed:     0.5,
+	VerdictUnverifiable: 0.3,
+	VerdictError:        0.2,
+}
+
+// Точные утверждения (числа, даты, код) ошибочны чаще и вреднее,
+// поэтому весят больше общих формулировок
+var claimTypeWeight = map[string]float64{
+	ClaimTypeCode:    1.3,
+	ClaimTypeDate:    1.2,
+	ClaimTypeNumeric: 1.2,
+	ClaimTypeEntity:  1.1,
+	ClaimTypeGeneral: 1,
+}
+
+const (
+	// Для подтверждённых: сомнение самой модели и слабость источника
+	residualFactualityRisk = 0.2
+	weakSourceRisk         = 0.2
+	outdatedRisk           = 0.2
+)
+
+// Названия факторов в разбивке
+const (
+	riskFactorRefuted      = "Опровергнутые утверждения"
+	riskFactorDisputed     = "Спорные утверждения"
+	riskFactorUnverifiable = "Непроверяемые утверждения"
+	riskFactorErrors       = "Ошибки проверки"
+	riskFactorLowFact      = "Неуверенные подтверждения"
+	riskFactorWeakSources  = "Слабые источники"
+	riskFactorOutdated     = "Возможно устаревшие данные"
+)
+
+// ScoreRisk сводит вердикты, factuality, типы утверждений и доверие к источникам
+// в оценку риска 0–100. Каждое утверждение даёт риск от 0 до 1 с весом своего типа;
+// оценка - взвешенное среднее, а баллы факторов в сумме дают итог.
+func ScoreRisk(results []FactCheckResult) RiskScore {
+	if len(results) == 0 {
+		return RiskScore{Level: riskLevel(0)}
+	}
+
+	points := make(map[string]float64)
+	counts := make(map[string]int)
+	add := func(factor string, weight, risk float64) {
+		if risk <= 0 {
+			return
+		}
+		points[factor] += weight * risk
+		counts[factor]++
+	}
+
+	totalWeight := 0.0
+	for _, r := range results {
+		weight := claimTypeWeight[r.ClaimType]
+		if weight == 0 {
+			weight = 1
+		}
+		totalWeight += weight
+
+		claimRisk := verdictRisk[r.Verdict]
+		switch r.Verdict {
+		case VerdictRefuted:
+			add(riskFactorRefuted, weight, claimRisk)
+		case VerdictDisputed:
+			add(riskFactorDisputed, weight, claimRisk)
+		case VerdictUnverifiable:
+			add(riskFactorUnverifiable, weight, claimRisk)
+		case VerdictError:
+			add(riskFactorErrors, weight, claimRisk)
+		case VerdictSupported:
+			lowFact := residualFactualityRisk * (1 - r.Factuality)
+			add(riskFactorLowFact, weight, lowFact)
+			claimRisk += lowFact
+			if r.SourceDomain != "" {
+				weak := weakSourceRisk * (1 - r.SourceCredibility)
+				add(riskFactorWeakSources, weight, weak)
+				claimRisk += weak
+			}
+		}
+
+		if r.PossiblyOutdated && claimRisk < 1 {
+			add(riskFactorOutdated, weight, math.Min(outdatedRisk, 1-claimRisk))
+		}
+	}
+
+	score := RiskScore{}
+	for _, name := range []string{
+		riskFactorRefuted, riskFactorDisputed, riskFactorUnverifiable, riskFactorErrors,
+		riskFactorLowFact, riskFactorWeakSources, riskFactorOutdated,
+	} {
+		if counts[name] == 0 {
+			continue
+		}
+		p := round1(100 * points[name] / totalWeight)
+		score.Factors = append(score.Factors, RiskFactor{Name: name, Points: p, Count: counts[name]})
+		score.Score += p
+	}
+
+	score.Score = math.Min(100, round1(score.Score))
+	score.Level = riskLevel(score.Score)
+	return score
+}
+
+func riskLevel(score float64) string {
+	switch {
+	case score < 25:
+		return "low"
+	case score < 50:
+		return "medium"
+	case score < 75:
+		return "high"
+	}
+	return "critical"
+}
+
+// riskLevelLabel - уровень риска по-русски для отчётов
+func riskLevelLabel(level string) string {
+	switch level {
+	case "low":
+		return "низкий"
+	case "medium":
+		return "средний"
+	case "high":
+		return "высокий"
+	case "critical":
+		return "критический"
+	}
+	return level
+}
+
+func round1(x float64) float64 {
+	return math.Round(x*10) / 10
+}
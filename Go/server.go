@@ -0,0 +1,407 @@
+// Go/server.go
+
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"net/http"
+	"os"
+	"os/signal"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	defaultServeAddr = ":8080"
+	maxRequestBody   = maxInputSize + 64<<10
+	defaultListLimit = 50
+
+	// checkTimeout - сколько может идти одна проверка длинного ответа; столько же
+	// сервер ждёт текущие проверки при остановке, чтобы не обрывать их
+	checkTimeout    = 10 * time.Minute
+	shutdownTimeout = checkTimeout
+
+	// maxStoredAnalyses - сколько последних проверок сервер держит в памяти
+	maxStoredAnalyses = 1000
+)
+
+// AnalyzeRequest - тело POST /api/analyses
+type AnalyzeRequest struct {
+	Query    string `json:"query"`
+	Response string `json:"response"`
+	AsOf     string `json:"as_of,omitempty"`
+	Model    string `json:"model,omitempty"`
+}
+
+// AnalysisRecord - проверка, сохранённая сервером
+type AnalysisRecord struct {
+	ID        string          `json:"id"`
+	CreatedAt time.Time       `json:"created_at"`
+	Analysis  *AnalysisResult `json:"analysis"`
+}
+
+// analysisListItem - краткая запись для GET /api/analyses
+type analysisListItem struct {
+	ID                string    `json:"id"`
+	CreatedAt         time.Time `json:"created_at"`
+	Query             string    `json:"query,omitempty"`
+	TotalClaims       int       `json:"total_claims"`
+	Refuted           int       `json:"refuted"`
+	HallucinationRate float64   `json:"hallucination_rate"`
+	RiskScore         float64   `json:"risk_score"`
+	PolicyStatus      string    `json:"policy_status,omitempty"`
+}
+
+// analysisStore хранит в памяти не больше maxStoredAnalyses проверок в порядке
+// поступления; самые старые вытесняются
+type analysisStore struct {
+	mu      sync.RWMutex
+	records map[string]*AnalysisRecord
+	order   []string
+}
+
+func newAnalysisStore() *analysisStore {
+	return &analysisStore{records: make(map[string]*AnalysisRecord)}
+}
+
+func (s *analysisStore) add(record *AnalysisRecord) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records[record.ID] = record
+	s.order = append(s.order, record.ID)
+	if len(s.order) > maxStoredAnalyses {
+		evicted := len(s.order) - maxStoredAnalyses
+		for _, id := range s.order[:evicted] {
+			delete(s.records, id)
+		}
+		s.order = slices.Delete(s.order, 0, evicted)
+	}
+}
+
+func (s *analysisStore) get(id string) (*AnalysisRecord, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	record, ok := s.records[id]
+	return record, ok
+}
+
+// list возвращает не больше limit последних проверок, новые первыми
+func (s *analysisStore) list(limit int) []analysisListItem {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	items := make([]analysisListItem, 0, min(limit, len(s.order)))
+	for i := len(s.order) - 1; i >= 0 && len(items) < limit; i-- {
+		r := s.records[s.order[i]]
+		item := analysisListItem{
+			ID:                r.ID,
+			CreatedAt:         r.CreatedAt,
+			Query:             truncateText(r.Analysis.Query, 100),
+			TotalClaims:       r.Analysis.Summary.TotalClaims,
+			Refuted:           r.Analysis.Summary.Refuted,
+			HallucinationRate: r.Analysis.Summary.HallucinationRate,
+			RiskScore:         r.Analysis.Risk.Score,
+		}
+		if r.Analysis.Policy != nil {
+			item.PolicyStatus = r.Analysis.Policy.Status
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// Server - REST API поверх пайплайна проверки
+type Server struct {
+	analyzer   *Analyzer
+	policyFile string
+	store      *analysisStore
+	jobs       *JobQueue
+}
+
+// NewServer создает сервер; policyFile применяется к каждой проверке
+func NewServer(analyzer *Analyzer, policyFile string) *Server {
+	return &Server{analyzer: analyzer, policyFile: policyFile, store: newAnalysisStore()}
+}
+
+// StartJobs включает фоновые задачи: загружает сохранённые в dir и запускает
+// workers обработчиков. Готовые результаты доступны и через /api/analyses.
+func (s *Server) StartJobs(dir string, workers int) error {
+	jobs, err := NewJobQueue(dir, workers, s.analyzer, s.policyFile, s.store.add)
+	if err != nil {
+		return err
+	}
+	s.jobs = jobs
+	return nil
+}
+
+// Handler - маршруты API
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /health", s.handleHealth)
+	mux.HandleFunc("POST /api/analyses", s.handleSubmit)
+	mux.HandleFunc("POST /api/analyses/stream", s.handleStream)
+	mux.HandleFunc("GET /api/analyses", s.handleList)
+	mux.HandleFunc("GET /api/analyses/{id}", s.handleGet)
+	if s.jobs != nil {
+		mux.HandleFunc("POST /api/jobs", s.handleJobSubmit)
+		mux.HandleFunc("GET /api/jobs", s.handleJobList)
+		mux.HandleFunc("GET /api/jobs/{id}", s.handleJobGet)
+		mux.HandleFunc("POST /api/jobs/{id}/cancel", s.handleJobCancel)
+		mux.HandleFunc("POST /api/jobs/{id}/retry", s.handleJobRetry)
+	}
+	return logRequests(mux)
+}
+
+// ListenAndServe работает до Ctrl+C или SIGTERM
+func (s *Server) ListenAndServe(addr string) error {
+	return serveUntilSignal(newHTTPServer(addr, s.Handler()))
+}
+
+// newHTTPServer - http.Server с таймаутами, общими для API и прокси
+func newHTTPServer(addr string, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       time.Minute,
+		// Проверка длинного ответа может занимать минуты
+		WriteTimeout: checkTimeout,
+		IdleTimeout:  2 * time.Minute,
+	}
+}
+
+// serveUntilSignal обслуживает запросы до Ctrl+C или SIGTERM, затем
+// дожидается текущих запросов (не дольше shutdownTimeout)
+func serveUntilSignal(srv *http.Server) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	errCh := make(chan error, 1)
+	go func() {
+		log.Printf("Сервер слушает %s", srv.Addr)
+		errCh <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		if !errors.Is(err, http.ErrServerClosed) {
+			return fmt.Errorf("ошибка сервера: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+	}
+
+	log.Printf("Остановка сервера...")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("не удалось корректно остановить сервер: %w", err)
+	}
+	return nil
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (s *Server) handleSubmit(w http.ResponseWriter, r *http.Request) {
+	req, opts, status, err := s.decodeAnalyzeRequest(w, r)
+	if err != nil {
+		writeError(w, status, err)
+		return
+	}
+
+	analysis, err := s.analyzer.Analyze(req.Query, req.Response, opts, nil)
+	if errors.Is(err, errNoClaims) {
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err)
+		return
+	}
+
+	record := &AnalysisRecord{ID: newAnalysisID(), CreatedAt: time.Now().UTC(), Analysis: analysis}
+	s.store.add(record)
+	w.Header().Set("Location", "/api/analyses/"+record.ID)
+	writeJSON(w, http.StatusCreated, record)
+}
+
+// handleStream проверяет ответ так же, как handleSubmit, но отдаёт ход
+// проверки как Server-Sent Events: note, extracted, claim для каждого
+// утверждения и summary в конце (или error). У всех событий один id,
+// по которому результат потом доступен через GET /api/analyses/{id}.
+func (s *Server) handleStream(w http.ResponseWriter, r *http.Request) {
+	req, opts, status, err := s.decodeAnalyzeRequest(w, r)
+	if err != nil {
+		writeError(w, status, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	rc := http.NewResponseController(w)
+	id := newAnalysisID()
+	send := func(e AnalysisEvent) {
+		// Клиент отключился - проверка доводится до конца и сохраняется, но не пишется
+		if r.Context().Err() != nil {
+			return
+		}
+		e.ID = id
+		data, err := json.Marshal(e)
+		if err != nil {
+			log.Printf("Ошибка сериализации события: %v", err)
+			return
+		}
+		fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.Type, data)
+		if err := rc.Flush(); err != nil {
+			log.Printf("Ошибка отправки события: %v", err)
+		}
+	}
+
+	// summary отправляется после сохранения, чтобы id уже был доступен
+	analysis, err := s.analyzer.Analyze(req.Query, req.Response, opts, func(e AnalysisEvent) {
+		if e.Type != EventSummary {
+			send(e)
+		}
+	})
+	if err != nil {
+		return
+	}
+	s.store.add(&AnalysisRecord{ID: id, CreatedAt: time.Now().UTC(), Analysis: analysis})
+	send(AnalysisEvent{Type: EventSummary, Analysis: analysis})
+}
+
+// decodeAnalyzeRequest читает и проверяет тело запроса; при ошибке
+// возвращает HTTP-статус, с которым нужно ответить
+func (s *Server) decodeAnalyzeRequest(w http.ResponseWriter, r *http.Request) (AnalyzeRequest, checkOptions, int, error) {
+	var req AnalyzeRequest
+	if status, err := decodeJSONBody(w, r, &req, maxRequestBody); err != nil {
+		return req, checkOptions{}, status, err
+	}
+	opts, status, err := s.validateAnalyzeRequest(req)
+	return req, opts, status, err
+}
+
+// decodeJSONBody читает JSON-объект из тела не больше limit байт; неизвестные поля - ошибка
+func decodeJSONBody(w http.ResponseWriter, r *http.Request, v any, limit int64) (int, error) {
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType != "application/json" {
+		return http.StatusUnsupportedMediaType, fmt.Errorf("ожидается Content-Type: application/json")
+	}
+
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, limit))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return http.StatusRequestEntityTooLarge, fmt.Errorf("тело запроса больше %d байт", tooLarge.Limit)
+		}
+		return http.StatusBadRequest, fmt.Errorf("некорректный JSON: %w", err)
+	}
+	if decoder.More() {
+		return http.StatusBadRequest, fmt.Errorf("в теле запроса больше одного JSON-объекта")
+	}
+	return http.StatusOK, nil
+}
+
+// validateAnalyzeRequest проверяет поля запроса и собирает параметры проверки
+func (s *Server) validateAnalyzeRequest(req AnalyzeRequest) (checkOptions, int, error) {
+	opts := checkOptions{PolicyFile: s.policyFile, Model: req.Model}
+	if strings.TrimSpace(req.Response) == "" {
+		return opts, http.StatusBadRequest, fmt.Errorf("поле response обязательно")
+	}
+	if len(req.Response) > maxInputSize {
+		return opts, http.StatusRequestEntityTooLarge, fmt.Errorf("ответ больше %d МБ", maxInputSize>>20)
+	}
+	if req.AsOf != "" {
+		date, err := parseReferenceDate(req.AsOf)
+		if err != nil {
+			return opts, http.StatusBadRequest, err
+		}
+		opts.ReferenceDate = date
+	}
+	return opts, http.StatusOK, nil
+}
+
+func (s *Server) handleList(w http.ResponseWriter, r *http.Request) {
+	limit := defaultListLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("limit должен быть положительным числом"))
+			return
+		}
+		limit = n
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"analyses": s.store.list(limit)})
+}
+
+func (s *Server) handleGet(w http.ResponseWriter, r *http.Request) {
+	record, ok := s.store.get(r.PathValue("id"))
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("проверка %s не найдена", r.PathValue("id")))
+		return
+	}
+	writeJSON(w, http.StatusOK, record)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		log.Printf("Ошибка записи ответа: %v", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+// newAnalysisID - случайный идентификатор проверки
+func newAnalysisID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// statusRecorder запоминает код ответа для журнала
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Unwrap нужен http.ResponseController, чтобы добраться до Flush исходного writer
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+func logRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		log.Printf("%s %s %d %s", r.Method, r.URL.Path, rec.status, time.Since(start).Round(time.Millisecond))
+	})
+}
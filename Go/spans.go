@@ -0,0 +1,206 @@
+// Go/spans.go
+
+package main
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Способ, которым найдено место утверждения в ответе
+const (
+	SpanExtractor = "extractor" // позиции вернул langextract
+	SpanExact     = "exact"     // текст утверждения буквально найден в ответе
+	SpanFuzzy     = "fuzzy"     // выбрано самое похожее предложение
+)
+
+// minSpanSimilarity - ниже этого сходства предложение не считается источником утверждения
+const minSpanSimilarity = 0.3
+
+// sentenceSpan - предложение ответа с позициями в символах
+type sentenceSpan struct {
+	start, end int
+	text       string
+}
+
+// splitSentences режет текст на предложения по . ! ? и переводам строк.
+// Позиции считаются в символах (рунах), как в Python API.
+func splitSentences(text string) []sentenceSpan {
+	var sentences []sentenceSpan
+	runes := []rune(text)
+	start := 0
+
+	flush := func(end int) {
+		segment := string(runes[start:end])
+		trimmed := strings.TrimSpace(segment)
+		if trimmed != "" {
+			lead := utf8.RuneCountInString(segment) - utf8.RuneCountInString(strings.TrimLeft(segment, " \t\r\n"))
+			s := start + lead
+			sentences = append(sentences, sentenceSpan{start: s, end: s + utf8.RuneCountInString(trimmed), text: trimmed})
+		}
+		start = end
+	}
+
+	for i, r := range runes {
+		switch r {
+		case '.', '!', '?':
+			// Не режем внутри чисел: 12.5, 3.14
+			if i+1 < len(runes) && runes[i+1] != ' ' && runes[i+1] != '\n' {
+				continue
+			}
+			flush(i + 1)
+		case '\n':
+			flush(i + 1)
+		}
+	}
+	if start < len(runes) {
+		flush(len(runes))
+	}
+
+	return sentences
+}
+
+// runeIndex ищет подстроку без учёта регистра и возвращает позицию в символах
+func runeIndex(text, substr string) int {
+	idx := strings.Index(strings.ToLower(text), strings.ToLower(substr))
+	if idx < 0 {
+		return -1
+	}
+	return utf8.RuneCountInString(strings.ToLower(text)[:idx])
+}
+
+// sentenceAt возвращает предложение, содержащее позицию pos
+func sentenceAt(sentences []sentenceSpan, pos int) string {
+	for _, s := range sentences {
+		if pos >= s.start && pos < s.end {
+			return s.text
+		}
+	}
+	return ""
+}
+
+// AlignClaim находит место утверждения в ответе: сначала буквально,
+// затем по самому похожему предложению. nil - если ничего похожего нет.
+func AlignClaim(response, claim string) *ClaimSpan {
+	claim = strings.TrimSpace(claim)
+	if claim == "" {
+		return nil
+	}
+	sentences := splitSentences(response)
+
+	if pos := runeIndex(response, claim); pos >= 0 {
+		return &ClaimSpan{
+			Claim:    claim,
+			Start:    intPtr(pos),
+			End:      intPtr(pos + utf8.RuneCountInString(claim)),
+			Sentence: sentenceAt(sentences, pos),
+			Method:   SpanExact,
+		}
+	}
+
+	claimWords, _ := normalizeClaim(claim)
+	best, bestScore := -1, 0.0
+	for i, s := range sentences {
+		words, _ := normalizeClaim(s.text)
+		if score := diceCoefficient(claimWords, words); score > bestScore {
+			best, bestScore = i, score
+		}
+	}
+	if best < 0 || bestScore < minSpanSimilarity {
+		return nil
+	}
+
+	s := sentences[best]
+	return &ClaimSpan{
+		Claim:    claim,
+		Start:    intPtr(s.start),
+		End:      intPtr(s.end),
+		Sentence: s.text,
+		Method:   SpanFuzzy,
+	}
+}
+
+// AlignResults привязывает каждый результат к месту в ответе. Позиции от
+// извлекателя берутся как есть, если они согласуются с текстом ответа;
+// иначе включается нечёткое выравнивание.
+func AlignResults(response string, results []FactCheckResult, spans []ClaimSpan) {
+	length := utf8.RuneCountInString(response)
+	bySpan := make(map[string]ClaimSpan, len(spans))
+	for _, s := range spans {
+		bySpan[s.Claim] = s
+	}
+
+	for i := range results {
+		r := &results[i]
+
+		if s, ok := bySpan[r.Claim]; ok && s.Start != nil && s.End != nil &&
+			*s.Start >= 0 && *s.Start < *s.End && *s.End <= length {
+			s.Method = SpanExtractor
+			if s.Sentence == "" {
+				s.Sentence = sentenceAt(splitSentences(response), *s.Start)
+			}
+			r.Span = &s
+			continue
+		}
+
+		needle := r.Claim
+		if r.Verifier == "gocode" {
+			needle = codeNeedle(r.Claim)
+		}
+		if span := AlignClaim(response, needle); span != nil {
+			span.Claim = r.Claim
+			r.Span = span
+		}
+	}
+}
+
+// rebaseSpans переносит позиции извлекателя из нормализованного текста (без
+// разметки и кода) в исходный ответ: фрагмент каждого span ищется в ответе.
+// Span, фрагмент которого в ответе не нашёлся, теряет позиции и выравнивается
+// заново в AlignResults.
+func rebaseSpans(spans []ClaimSpan, normalized, original string) []ClaimSpan {
+	if normalized == original {
+		return spans
+	}
+	rebased := make([]ClaimSpan, len(spans))
+	for i, s := range spans {
+		fragment := SpanText(normalized, &s)
+		s.Start, s.End, s.Sentence = nil, nil, ""
+		if fragment != "" {
+			if pos := runeIndex(original, fragment); pos >= 0 {
+				s.Start = intPtr(pos)
+				s.End = intPtr(pos + utf8.RuneCountInString(fragment))
+			}
+		}
+		rebased[i] = s
+	}
+	return rebased
+}
+
+// codeNeedle - фрагмент кода, который стоит искать в ответе для результата gocode:
+// "Go: strings.Reverse" -> "strings.Reverse", "Go: import \"x/y\"" -> "\"x/y\""
+func codeNeedle(claim string) string {
+	claim = strings.TrimPrefix(claim, "Go: ")
+	if rest, ok := strings.CutPrefix(claim, "import "); ok {
+		return rest
+	}
+	needle, _, _ := strings.Cut(claim, " ")
+	needle, _, _ = strings.Cut(needle, "{")
+	return needle
+}
+
+// SpanText возвращает текст ответа в границах span (позиции в символах)
+func SpanText(response string, span *ClaimSpan) string {
+	if span == nil || span.Start == nil || span.End == nil {
+		return ""
+	}
+	runes := []rune(response)
+	if *span.Start < 0 || *span.End > len(runes) || *span.Start >= *span.End {
+		return ""
+	}
+	return string(runes[*span.Start:*span.End])
+}
+
+func intPtr(v int) *int {
+	return &v
+}
@@ -0,0 +1,417 @@
+// Go/stats.go
+
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/muesli/termenv"
+)
+
+const (
+	defaultStatsDays = 30
+	statsTopRefuted  = 10
+	statsBarWidth    = 30
+)
+
+// sparkRunes - уровни спарклайна от меньшего к большему
+var sparkRunes = []rune("▁▂▃▄▅▆▇█")
+
+// StatsFilter - какие проверки истории учитывать
+type StatsFilter struct {
+	Since time.Time
+	Model string
+}
+
+// RateBucket - доля галлюцинаций в группе проверок. Label - ключ группы
+// (день YYYY-MM-DD, метка модели, тип утверждения или тема); Rate, как и
+// в ResultSummary, считается только по проверенным утверждениям.
+type RateBucket struct {
+	Label    string
+	Analyses int
+	Claims   int
+	Verified int
+	Refuted  int
+	Rate     float64
+}
+
+// RefutedClaim - утверждение, которое опровергалось чаще других
+type RefutedClaim struct {
+	Claim string
+	Count int
+}
+
+// VerifierTiming - скорость проверщика по сохранённым замерам
+type VerifierTiming struct {
+	Verifier string
+	Claims   int
+	AvgMs    float64
+	MaxMs    int64
+}
+
+// HistoryStats - сводка по истории за период
+type HistoryStats struct {
+	Filter      StatsFilter
+	Total       RateBucket
+	Daily       []RateBucket // все дни периода по UTC, включая дни без проверок
+	ByModel     []RateBucket
+	ByClaimType []RateBucket
+	ByTopic     []RateBucket
+	TopRefuted  []RefutedClaim
+	Verifiers   []VerifierTiming
+}
+
+// statsWhere - условие отбора проверок по фильтру
+func statsWhere(f StatsFilter) (string, []any) {
+	where := "a.created_at >= ?"
+	args := []any{f.Since.UTC().Format(time.RFC3339Nano)}
+	if f.Model != "" {
+		where += " AND a.model = ?"
+		args = append(args, f.Model)
+	}
+	return where, args
+}
+
+// Stats собирает статистику по истории
+func (h *HistoryStore) Stats(f StatsFilter) (*HistoryStats, error) {
+	stats := &HistoryStats{Filter: f}
+
+	total, err := h.rateBuckets(f, "''", "LEFT JOIN")
+	if err != nil {
+		return nil, err
+	}
+	if len(total) > 0 {
+		stats.Total = total[0]
+	}
+
+	daily, err := h.rateBuckets(f, "substr(a.created_at, 1, 10)", "LEFT JOIN")
+	if err != nil {
+		return nil, err
+	}
+	stats.Daily = fillDays(daily, f.Since, time.Now().UTC())
+
+	if stats.ByModel, err = h.rateBuckets(f, "a.model", "LEFT JOIN"); err != nil {
+		return nil, err
+	}
+	if stats.ByClaimType, err = h.rateBuckets(f, "c.claim_type", "JOIN"); err != nil {
+		return nil, err
+	}
+	if stats.ByTopic, err = h.rateBuckets(f, "a.topic", "LEFT JOIN"); err != nil {
+		return nil, err
+	}
+	for _, buckets := range [][]RateBucket{stats.ByModel, stats.ByClaimType, stats.ByTopic} {
+		sort.SliceStable(buckets, func(i, j int) bool {
+			if buckets[i].Rate != buckets[j].Rate {
+				return buckets[i].Rate > buckets[j].Rate
+			}
+			return buckets[i].Verified > buckets[j].Verified
+		})
+	}
+
+	if stats.TopRefuted, err = h.topRefuted(f, statsTopRefuted); err != nil {
+		return nil, err
+	}
+	if stats.Verifiers, err = h.verifierTimings(f); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// rateBuckets группирует проверки по выражению group; join - LEFT JOIN,
+// чтобы учитывать проверки без утверждений, или JOIN для групп по утверждениям
+func (h *HistoryStore) rateBuckets(f StatsFilter, group, join string) ([]RateBucket, error) {
+	where, args := statsWhere(f)
+	rows, err := h.db.Query(`SELECT `+group+`, COUNT(DISTINCT a.id), COUNT(c.position),
+		COALESCE(SUM(c.verdict IN ('supported', 'refuted', 'disputed')), 0),
+		COALESCE(SUM(c.verdict = 'refuted'), 0)
+		FROM analyses a `+join+` claims c ON c.analysis_id = a.id
+		WHERE `+where+` GROUP BY 1 ORDER BY 1`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка статистики: %w", err)
+	}
+	defer rows.Close()
+
+	var buckets []RateBucket
+	for rows.Next() {
+		var b RateBucket
+		if err := rows.Scan(&b.Label, &b.Analyses, &b.Claims, &b.Verified, &b.Refuted); err != nil {
+			return nil, fmt.Errorf("ошибка статистики: %w", err)
+		}
+		if b.Verified > 0 {
+			b.Rate = float64(b.Refuted) / float64(b.Verified)
+		}
+		buckets = append(buckets, b)
+	}
+	return buckets, rows.Err()
+}
+
+func (h *HistoryStore) topRefuted(f StatsFilter, limit int) ([]RefutedClaim, error) {
+	where, args := statsWhere(f)
+	rows, err := h.db.Query(`SELECT c.claim, COUNT(*) FROM claims c JOIN analyses a ON a.id = c.analysis_id
+		WHERE c.verdict = 'refuted' AND `+where+`
+		GROUP BY c.claim ORDER BY 2 DESC, MAX(a.id) DESC LIMIT ?`, append(args, limit)...)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка статистики: %w", err)
+	}
+	defer rows.Close()
+
+	var claims []RefutedClaim
+	for rows.Next() {
+		var c RefutedClaim
+		if err := rows.Scan(&c.Claim, &c.Count); err != nil {
+			return nil, fmt.Errorf("ошибка статистики: %w", err)
+		}
+		claims = append(claims, c)
+	}
+	return claims, rows.Err()
+}
+
+// verifierTimings - проверщики от самого медленного в среднем
+func (h *HistoryStore) verifierTimings(f StatsFilter) ([]VerifierTiming, error) {
+	where, args := statsWhere(f)
+	rows, err := h.db.Query(`SELECT c.verifier, COUNT(*), AVG(c.duration_ms), MAX(c.duration_ms)
+		FROM claims c JOIN analyses a ON a.id = c.analysis_id
+		WHERE c.verifier <> '' AND `+where+`
+		GROUP BY c.verifier ORDER BY 3 DESC`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка статистики: %w", err)
+	}
+	defer rows.Close()
+
+	var timings []VerifierTiming
+	for rows.Next() {
+		var t VerifierTiming
+		if err := rows.Scan(&t.Verifier, &t.Claims, &t.AvgMs, &t.MaxMs); err != nil {
+			return nil, fmt.Errorf("ошибка статистики: %w", err)
+		}
+		timings = append(timings, t)
+	}
+	return timings, rows.Err()
+}
+
+// fillDays дополняет дневные группы пустыми днями, чтобы спарклайн шёл по календарю
+func fillDays(days []RateBucket, from, to time.Time) []RateBucket {
+	byDay := make(map[string]RateBucket, len(days))
+	for _, d := range days {
+		byDay[d.Label] = d
+	}
+	var filled []RateBucket
+	for day := from.UTC().Truncate(24 * time.Hour); !day.After(to); day = day.AddDate(0, 0, 1) {
+		label := day.Format(time.DateOnly)
+		if d, ok := byDay[label]; ok {
+			filled = append(filled, d)
+		} else {
+			filled = append(filled, RateBucket{Label: label})
+		}
+	}
+	return filled
+}
+
+// sparkline рисует долю галлюцинаций по дням относительно худшего дня;
+// дни без проверенных утверждений - точкой
+func sparkline(days []RateBucket) string {
+	peak := 0.0
+	for _, d := range days {
+		peak = max(peak, d.Rate)
+	}
+	var b strings.Builder
+	for _, d := range days {
+		switch {
+		case d.Verified == 0:
+			b.WriteRune('·')
+		case peak == 0:
+			b.WriteRune(sparkRunes[0])
+		default:
+			level := int(math.Round(d.Rate / peak * float64(len(sparkRunes)-1)))
+			b.WriteRune(sparkRunes[level])
+		}
+	}
+	return b.String()
+}
+
+// bar - полоса длиной value/peak от statsBarWidth
+func bar(value, peak float64) string {
+	if peak <= 0 {
+		return ""
+	}
+	n := int(math.Round(value / peak * statsBarWidth))
+	if n == 0 && value > 0 {
+		return "▏"
+	}
+	return strings.Repeat("█", n)
+}
+
+// rateColor - цвет доли галлюцинаций: до 10% норма, до 30% - внимание
+func rateColor(p termenv.Profile, rate float64) termenv.Color {
+	switch {
+	case rate < 0.1:
+		return p.Color("#3FB950")
+	case rate < 0.3:
+		return p.Color("#D29922")
+	}
+	return p.Color("#FF6B6B")
+}
+
+// printStats - отчёт по истории в терминале
+func printStats(stats *HistoryStats, days int) {
+	p := termenv.ColorProfile()
+	colorHeader := p.Color("#00BFFF")
+	colorDim := p.Color("#8B949E")
+	colorText := p.Color("#E6EDF3")
+
+	fmt.Println()
+	fmt.Println(termenv.String("  ══════════════════════════════════════════").Foreground(colorHeader))
+	fmt.Println(termenv.String("            СТАТИСТИКА ПРОВЕРОК             ").Foreground(colorText))
+	fmt.Println(termenv.String("  ══════════════════════════════════════════").Foreground(colorHeader))
+
+	period := fmt.Sprintf("  За %d дн.", days)
+	if stats.Filter.Model != "" {
+		period += ", модель " + stats.Filter.Model
+	}
+	fmt.Println(termenv.String(period).Foreground(colorDim))
+
+	t := stats.Total
+	if t.Analyses == 0 {
+		fmt.Println(termenv.String("\n  За период проверок нет").Foreground(colorDim))
+		return
+	}
+	fmt.Printf("\n  Проверок: %d   утверждений: %d   опровергнуто: %d   ", t.Analyses, t.Claims, t.Refuted)
+	fmt.Println(termenv.String(fmt.Sprintf("галлюцинаций: %.1f%%", t.Rate*100)).Foreground(rateColor(p, t.Rate)).Bold())
+
+	fmt.Println(termenv.String("\n  📈 Доля галлюцинаций по дням").Foreground(colorHeader).Bold())
+	peak, last := 0.0, "—"
+	for _, d := range stats.Daily {
+		peak = max(peak, d.Rate)
+		if d.Verified > 0 {
+			last = fmt.Sprintf("%.0f%%", d.Rate*100)
+		}
+	}
+	fmt.Println("     " + termenv.String(sparkline(stats.Daily)).Foreground(rateColor(p, t.Rate)).String())
+	fmt.Println(termenv.String(fmt.Sprintf("     %s … %s   худший день %.0f%%, последний %s",
+		stats.Daily[0].Label, stats.Daily[len(stats.Daily)-1].Label, peak*100, last)).Foreground(colorDim))
+
+	printRateBars("🏷  По моделям", stats.ByModel, func(label string) string {
+		if label == "" {
+			return "(без метки)"
+		}
+		return label
+	})
+	printRateBars("🧩 По типам утверждений", stats.ByClaimType, claimTypeLabel)
+	printRateBars("📚 По темам", stats.ByTopic, topicLabel)
+
+	if len(stats.TopRefuted) > 0 {
+		fmt.Println(termenv.String("\n  ❌ Чаще всего опровергаются").Foreground(colorHeader).Bold())
+		for _, c := range stats.TopRefuted {
+			fmt.Printf("     %3d× %s\n", c.Count, truncateText(c.Claim, 80))
+		}
+	}
+
+	if len(stats.Verifiers) > 0 {
+		fmt.Println(termenv.String("\n  ⏱  Скорость проверщиков (в среднем на утверждение)").Foreground(colorHeader).Bold())
+		slowest := stats.Verifiers[0].AvgMs
+		for _, v := range stats.Verifiers {
+			fmt.Printf("     %-16s %s %s\n", v.Verifier,
+				termenv.String(fmt.Sprintf("%-*s", statsBarWidth, bar(v.AvgMs, slowest))).Foreground(colorHeader),
+				termenv.String(fmt.Sprintf("%6.0f мс (макс %d мс, n=%d)", v.AvgMs, v.MaxMs, v.Claims)).Foreground(colorDim))
+		}
+	}
+}
+
+// printRateBars - полосы доли галлюцинаций по группам относительно худшей
+func printRateBars(title string, buckets []RateBucket, label func(string) string) {
+	if len(buckets) == 0 {
+		return
+	}
+	p := termenv.ColorProfile()
+	colorHeader := p.Color("#00BFFF")
+	colorDim := p.Color("#8B949E")
+
+	fmt.Println(termenv.String("\n  " + title).Foreground(colorHeader).Bold())
+	peak := 0.0
+	for _, b := range buckets {
+		peak = max(peak, b.Rate)
+	}
+	for _, b := range buckets {
+		fmt.Printf("     %-16s %s %s\n", truncateText(label(b.Label), 16),
+			termenv.String(fmt.Sprintf("%-*s", statsBarWidth, bar(b.Rate, peak))).Foreground(rateColor(p, b.Rate)),
+			termenv.String(fmt.Sprintf("%5.1f%% (%d/%d)", b.Rate*100, b.Refuted, b.Verified)).Foreground(colorDim))
+	}
+}
+
+// ExportStatsCSV сохраняет статистику в CSV, по строке на группу;
+// path "-" - в stdout. Пустые ячейки - неприменимые для раздела столбцы.
+func ExportStatsCSV(stats *HistoryStats, path string) error {
+	var out io.Writer = os.Stdout
+	if path != "-" {
+		f, err := os.Create(path)
+		if err != nil {
+			return fmt.Errorf("не удалось создать файл: %w", err)
+		}
+		defer f.Close()
+		out = f
+	}
+
+	w := csv.NewWriter(out)
+	w.Write([]string{"section", "label", "analyses", "claims", "verified", "refuted", "hallucination_rate", "count", "avg_ms", "max_ms"})
+
+	itoa := strconv.Itoa
+	rate := func(r float64) string { return strconv.FormatFloat(r, 'f', 4, 64) }
+	buckets := func(section string, list []RateBucket) {
+		for _, b := range list {
+			w.Write([]string{section, b.Label, itoa(b.Analyses), itoa(b.Claims), itoa(b.Verified), itoa(b.Refuted), rate(b.Rate), "", "", ""})
+		}
+	}
+
+	buckets("total", []RateBucket{stats.Total})
+	buckets("day", stats.Daily)
+	buckets("model", stats.ByModel)
+	buckets("claim_type", stats.ByClaimType)
+	buckets("topic", stats.ByTopic)
+	for _, c := range stats.TopRefuted {
+		w.Write([]string{"refuted_claim", c.Claim, "", "", "", "", "", itoa(c.Count), "", ""})
+	}
+	for _, v := range stats.Verifiers {
+		w.Write([]string{"verifier", v.Verifier, "", itoa(v.Claims), "", "", "", "",
+			strconv.FormatFloat(v.AvgMs, 'f', 0, 64), strconv.FormatInt(v.MaxMs, 10)})
+	}
+
+	w.Flush()
+	return w.Error()
+}
+
+// runStats собирает и показывает статистику; csvPath - куда выгрузить CSV
+func runStats(days int, model, csvPath string, p termenv.Profile) error {
+	if days < 1 {
+		return fmt.Errorf("число дней должно быть положительным")
+	}
+	history, err := sharedHistory()
+	if err != nil {
+		return fmt.Errorf("история недоступна: %w", err)
+	}
+
+	since := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -(days - 1))
+	stats, err := history.Stats(StatsFilter{Since: since, Model: model})
+	if err != nil {
+		return err
+	}
+
+	if csvPath == "-" {
+		return ExportStatsCSV(stats, csvPath)
+	}
+	printStats(stats, days)
+	if csvPath != "" {
+		if err := ExportStatsCSV(stats, csvPath); err != nil {
+			return err
+		}
+		fmt.Println(termenv.String(fmt.Sprintf("\n  ✅ CSV сохранён в %s", csvPath)).Foreground(p.Color("#8B949E")))
+	}
+	return nil
+}
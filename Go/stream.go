@@ -0,0 +1,326 @@
+// Go/stream.go
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// streamWorkers - сколько предложений одновременно извлекается и сколько
+// утверждений одновременно проверяется при потоковой проверке
+const streamWorkers = 3
+
+// sentenceSegmenter собирает поток токенов в законченные предложения.
+// Предложение считается законченным, когда после . ! ? … пришёл пробел
+// или закончилась строка; содержимое блоков кода пропускается.
+type sentenceSegmenter struct {
+	pending []rune
+	inFence bool
+}
+
+// push добавляет токен и возвращает предложения, которые он завершил
+func (s *sentenceSegmenter) push(token string) []string {
+	s.pending = append(s.pending, []rune(token)...)
+
+	var done []string
+	start := 0
+	for i, r := range s.pending {
+		end := 0
+		switch r {
+		case '\n':
+			end = i + 1
+		case '.', '!', '?', '…':
+			// Не режем внутри чисел и пока не пришёл следующий символ
+			if i+1 < len(s.pending) && unicode.IsSpace(s.pending[i+1]) {
+				end = i + 1
+			}
+		}
+		if end > 0 {
+			done = s.appendSentence(done, string(s.pending[start:end]))
+			start = end
+		}
+	}
+	s.pending = s.pending[start:]
+	return done
+}
+
+// flush возвращает недописанный остаток в конце потока
+func (s *sentenceSegmenter) flush() []string {
+	done := s.appendSentence(nil, string(s.pending))
+	s.pending = nil
+	return done
+}
+
+func (s *sentenceSegmenter) appendSentence(done []string, segment string) []string {
+	if fencePattern.MatchString(segment) {
+		s.inFence = !s.inFence
+		return done
+	}
+	if s.inFence {
+		return done
+	}
+	if text := strings.TrimSpace(NormalizeInput(segment)); text != "" {
+		done = append(done, text)
+	}
+	return done
+}
+
+// chatStreamDelta разбирает строку SSE-потока chat/completions (OpenAI)
+// и возвращает текст из choices[0].delta; done - пришёл "data: [DONE]"
+func chatStreamDelta(line string) (text string, done bool) {
+	data, ok := strings.CutPrefix(strings.TrimRight(line, "\r\n"), "data:")
+	if !ok {
+		return "", false
+	}
+	data = strings.TrimSpace(data)
+	if data == "[DONE]" {
+		return "", true
+	}
+
+	var chunk struct {
+		Choices []struct {
+			Delta struct {
+				Content json.RawMessage `json:"content"`
+			} `json:"delta"`
+		} `json:"choices"`
+	}
+	if err := json.Unmarshal([]byte(data), &chunk); err != nil || len(chunk.Choices) == 0 {
+		return "", false
+	}
+	text, _ = messageText(chunk.Choices[0].Delta.Content)
+	return text, false
+}
+
+// ReadChatStream читает SSE-поток chat/completions и передаёт токены
+// в tokens; канал закрывается по [DONE] или концу потока
+func ReadChatStream(r io.Reader, tokens chan<- string) error {
+	defer close(tokens)
+
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadString('\n')
+		if text, done := chatStreamDelta(line); done {
+			return nil
+		} else if text != "" {
+			tokens <- text
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("ошибка чтения потока: %w", err)
+		}
+	}
+}
+
+// streamItem - утверждение на пути от извлечения к выдаче. Предложение без
+// утверждений (или с ошибкой извлечения) проходит одним элементом с count = 0,
+// чтобы упорядочивание знало, что его больше не нужно ждать.
+type streamItem struct {
+	sentence int
+	claim    int
+	count    int
+	text     string
+	result   FactCheckResult
+	err      error
+}
+
+// AnalyzeStream проверяет ответ, пока модель его ещё пишет: токены
+// собираются в предложения, предложения параллельно отправляются на
+// извлечение, утверждения - на проверку. Этапы связаны каналами, а события
+// claim приходят в onEvent в порядке предложений ответа, из вызывающей
+// горутины. Возвращается, когда канал tokens закрыт и всё проверено.
+func (a *Analyzer) AnalyzeStream(query string, tokens <-chan string, opts checkOptions, onEvent func(AnalysisEvent)) (*AnalysisResult, error) {
+	run := newAnalysisRun(onEvent)
+	emit, notify := run.emit, run.notify
+
+	stages := loadClaimStages(notify)
+	api := NewJinaClient(a.jinaKey)
+	api.ReferenceDate = opts.ReferenceDate
+
+	type sentenceJob struct {
+		seq  int
+		text string
+	}
+	sentences := make(chan sentenceJob)
+	claims := make(chan streamItem)
+	verified := make(chan streamItem)
+
+	// Сегментация: токены принимаются сразу, даже если извлечение не успевает, -
+	// иначе тормозил бы поток, который параллельно идёт клиенту. Весь текст
+	// копится для итогового результата и проверки кода.
+	var raw strings.Builder
+	go func() {
+		defer close(sentences)
+		var seg sentenceSegmenter
+		var queue []sentenceJob
+		seq := 0
+		enqueue := func(done []string) {
+			for _, text := range done {
+				queue = append(queue, sentenceJob{seq: seq, text: text})
+				seq++
+			}
+		}
+
+		in := tokens
+		for in != nil || len(queue) > 0 {
+			var out chan<- sentenceJob
+			var head sentenceJob
+			if len(queue) > 0 {
+				out, head = sentences, queue[0]
+			}
+			select {
+			case token, ok := <-in:
+				if !ok {
+					in = nil
+					enqueue(seg.flush())
+					continue
+				}
+				raw.WriteString(token)
+				enqueue(seg.push(token))
+			case out <- head:
+				queue = queue[1:]
+			}
+		}
+	}()
+
+	// Извлечение
+	var extractors sync.WaitGroup
+	for range streamWorkers {
+		extractors.Add(1)
+		go func() {
+			defer extractors.Done()
+			for job := range sentences {
+				found, err := a.python.ExtractClaims(job.text)
+				if err != nil || len(found) == 0 {
+					claims <- streamItem{sentence: job.seq, text: job.text, err: err}
+					continue
+				}
+				for i, claim := range found {
+					claims <- streamItem{sentence: job.seq, claim: i, count: len(found), text: job.text, result: FactCheckResult{Claim: claim}}
+				}
+			}
+		}()
+	}
+	go func() {
+		extractors.Wait()
+		close(claims)
+	}()
+
+	// Проверка: сначала база мифов, затем Jina
+	var verifiers sync.WaitGroup
+	for range streamWorkers {
+		verifiers.Add(1)
+		go func() {
+			defer verifiers.Done()
+			for item := range claims {
+				if item.count > 0 {
+					// Контекст времени и место в ответе зависят от всего
+					// ответа и уточняются, когда поток закончится
+					one := []FactCheckResult{a.verifyClaim(stages, api, item.result.Claim)}
+					stages.finalize(one, "", opts.ReferenceDate, nil)
+					item.result = one[0]
+				}
+				verified <- item
+			}
+		}()
+	}
+	go func() {
+		verifiers.Wait()
+		close(verified)
+	}()
+
+	// Упорядочивание: предложение выдаётся, когда проверены все его утверждения
+	// и выданы все предыдущие
+	var results []FactCheckResult
+	waiting := make(map[int][]streamItem)
+	next := 0
+	for item := range verified {
+		waiting[item.sentence] = append(waiting[item.sentence], item)
+		for {
+			group := waiting[next]
+			if len(group) == 0 || len(group) < group[0].count {
+				break
+			}
+			delete(waiting, next)
+			next++
+
+			if group[0].err != nil {
+				notify(noteWarn, "Предложение %d не разобрано: %v", next, group[0].err)
+				continue
+			}
+			if group[0].count == 0 {
+				continue
+			}
+			sort.Slice(group, func(i, j int) bool { return group[i].claim < group[j].claim })
+			for _, g := range group {
+				results = append(results, g.result)
+				r := g.result
+				emit(AnalysisEvent{Type: EventClaim, Index: len(results), Result: &r})
+			}
+		}
+	}
+
+	response := raw.String()
+	codeResults := NewGoCodeChecker().CheckResponse(response)
+	if len(codeResults) > 0 {
+		notify(noteInfo, "🧩 В Go-коде найдено несуществующих идентификаторов: %d", len(codeResults))
+		for i := range codeResults {
+			stages.finalize(codeResults[i:i+1], response, opts.ReferenceDate, nil)
+			results = append(results, codeResults[i])
+			r := codeResults[i]
+			emit(AnalysisEvent{Type: EventClaim, Index: len(results), Result: &r})
+		}
+	}
+
+	if len(results) == 0 {
+		emit(AnalysisEvent{Type: EventError, Message: errNoClaims.Error()})
+		return nil, errNoClaims
+	}
+
+	// Теперь ответ известен целиком: дата знаний модели берётся из всего
+	// текста, а не из одного предложения, и ищется место каждого утверждения
+	claimTexts := make([]string, len(results))
+	for i := range results {
+		results[i].Span = nil
+		claimTexts[i] = results[i].Claim
+	}
+	ApplyTemporalContext(results, response, opts.ReferenceDate)
+	AlignResults(response, results, nil)
+
+	analysis := &AnalysisResult{
+		Query:            query,
+		Response:         response,
+		Claims:           claimTexts,
+		FactCheckResults: results,
+		Summary:          BuildSummary(results),
+		Risk:             ScoreRisk(results),
+		Relevance:        ScoreRelevance(query, NormalizeInput(response), results),
+	}
+
+	return a.complete(run, analysis, opts), nil
+}
+
+// verifyClaim проверяет одно утверждение: известный миф или запрос к Jina
+func (a *Analyzer) verifyClaim(stages *claimStages, api *JinaClient, claim string) FactCheckResult {
+	if stages.myths != nil {
+		if r, ok := stages.myths.CheckClaim(claim); ok {
+			return r
+		}
+	}
+	started := time.Now()
+	result, err := api.CheckClaim(claim)
+	if err != nil {
+		result = FactCheckResult{Claim: claim, Found: false, Verifier: "jina", Error: err.Error()}
+	}
+	result.DurationMs = time.Since(started).Milliseconds()
+	return result
+}
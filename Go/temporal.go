@@ -0,0 +1,166 @@
+// Go/temporal.go
+
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// Признаки утверждений, истинность которых зависит от времени
+	timeSensitiveEN = regexp.MustCompile(`(?i)\b(current(ly)?|now|today|latest|recent(ly)?|as of|incumbent|president|prime minister|ceo|chairman|population|price[sd]?|costs?|exchange rate|record|champion|version|leads?|ranks?)\b`)
+	timeSensitiveRU = []string{
+		"текущ", "нынешн", "сейчас", "в настоящее время", "на данный момент", "сегодня",
+		"последн", "действующ", "президент", "премьер", "директор", "глава ", "возглавля",
+		"население", "насчитыва", "курс ", "цена", "стоит ", "стоимост", "рекорд", "чемпион",
+		"версия", "занимает",
+	}
+
+	// Явные указания модели на дату своих знаний: "as of 2023", "по состоянию на 2022 год"
+	knowledgeDatePattern = regexp.MustCompile(`(?i)(?:as of|as at|knowledge cutoff|last (?:knowledge )?update|training data|по состоянию на|на момент|на начало|на конец|обновлени[яе] (?:моих )?знаний)[^\d]{0,30}((?:19|20)\d{2})(?:-(\d{2}))?`)
+
+	yearPattern    = regexp.MustCompile(`\b(?:19|20)\d{2}\b`)
+	urlYearPattern = regexp.MustCompile(`/((?:19|20)\d{2})(?:[/-](0[1-9]|1[0-2]))?(?:/|-|$)`)
+
+	// Даты с месяцем в цитате: "2024-05", "May 2024", "5 мая 2024"
+	isoMonthPattern  = regexp.MustCompile(`\b((?:19|20)\d{2})-(0[1-9]|1[0-2])\b`)
+	monthYearPattern = regexp.MustCompile(`(?i)(?:^|[^\p{L}])(january|february|march|april|may|june|july|august|september|october|november|december|jan|feb|mar|apr|jun|jul|aug|sept?|oct|nov|dec|январ\p{L}*|феврал\p{L}*|март\p{L}*|апрел\p{L}*|ма[йяе]|июн\p{L}*|июл\p{L}*|август\p{L}*|сентябр\p{L}*|октябр\p{L}*|ноябр\p{L}*|декабр\p{L}*)\.?\s+(?:\d{1,2},?\s+)?((?:19|20)\d{2})\b`)
+	monthStems       = [][2]string{
+		{"jan", "январ"}, {"feb", "феврал"}, {"mar", "март"}, {"apr", "апрел"},
+		{"may", "ма"}, {"jun", "июн"}, {"jul", "июл"}, {"aug", "август"},
+		{"sep", "сентябр"}, {"oct", "октябр"}, {"nov", "ноябр"}, {"dec", "декабр"},
+	}
+)
+
+// IsTimeSensitive отмечает утверждения вроде "нынешний президент" или "население 12 млн"
+func IsTimeSensitive(claim string) bool {
+	if timeSensitiveEN.MatchString(claim) {
+		return true
+	}
+	lower := strings.ToLower(claim)
+	for _, marker := range timeSensitiveRU {
+		if strings.Contains(lower, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseReferenceDate принимает YYYY, YYYY-MM или YYYY-MM-DD
+func parseReferenceDate(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range []string{"2006-01-02", "2006-01", "2006"} {
+		if _, err := time.Parse(layout, value); err == nil {
+			return value, nil
+		}
+	}
+	return "", fmt.Errorf("неверная дата %q, ожидается YYYY, YYYY-MM или YYYY-MM-DD", value)
+}
+
+// ImpliedKnowledgeDate ищет в ответе дату, на которую модель "знает" факты.
+// Если упоминаний несколько, берётся самое позднее.
+func ImpliedKnowledgeDate(response string) string {
+	best := ""
+	for _, m := range knowledgeDatePattern.FindAllStringSubmatch(response, -1) {
+		date := m[1]
+		if m[2] != "" {
+			date += "-" + m[2]
+		}
+		if date > best {
+			best = date
+		}
+	}
+	return best
+}
+
+// evidenceDate оценивает дату источника: самая поздняя дата в цитате, иначе
+// дата из пути URL (/2024/05/...). Дата - YYYY-MM, если известен месяц, иначе YYYY.
+func evidenceDate(keyQuote, sourceURL string) string {
+	maxYear := time.Now().Year() + 1
+	best := ""
+	consider := func(year, month string) {
+		if y, _ := strconv.Atoi(year); y > maxYear {
+			return
+		}
+		date := year
+		if month != "" {
+			date += "-" + month
+		}
+		// YYYY-MM сравниваются как строки; при равном годе дата с месяцем точнее
+		if date > best {
+			best = date
+		}
+	}
+
+	for _, y := range yearPattern.FindAllString(keyQuote, -1) {
+		consider(y, "")
+	}
+	for _, m := range isoMonthPattern.FindAllStringSubmatch(keyQuote, -1) {
+		consider(m[1], m[2])
+	}
+	for _, m := range monthYearPattern.FindAllStringSubmatch(keyQuote, -1) {
+		consider(m[2], monthNumber(m[1]))
+	}
+	if best == "" {
+		if m := urlYearPattern.FindStringSubmatch(sourceURL); m != nil {
+			consider(m[1], m[2])
+		}
+	}
+	return best
+}
+
+// monthNumber - номер месяца "01".."12" по названию на английском или русском
+func monthNumber(name string) string {
+	name = strings.ToLower(name)
+	for i, stems := range monthStems {
+		if strings.HasPrefix(name, stems[0]) || strings.HasPrefix(name, stems[1]) {
+			return fmt.Sprintf("%02d", i+1)
+		}
+	}
+	return ""
+}
+
+func dateYear(date string) string {
+	if len(date) < 4 {
+		return ""
+	}
+	return date[:4]
+}
+
+// datesDiffer сравнивает даты с точностью до месяца, если он есть у обеих, иначе до года
+func datesDiffer(a, b string) bool {
+	if len(a) >= 7 && len(b) >= 7 {
+		return a[:7] != b[:7]
+	}
+	return dateYear(a) != dateYear(b)
+}
+
+// ApplyTemporalContext размечает зависящие от времени утверждения и выставляет
+// "возможно устарело", когда дата источника не совпадает с датой знаний модели
+// (с точностью до месяца, если он известен у обеих дат). referenceDate задаётся
+// пользователем и важнее всего - на эту же дату спрашивается Jina; если пусто -
+// дата знаний берётся из текста ответа. Повторный вызов с полным ответом
+// пересчитывает разметку.
+func ApplyTemporalContext(results []FactCheckResult, response, referenceDate string) {
+	asOf := referenceDate
+	if asOf == "" {
+		asOf = ImpliedKnowledgeDate(response)
+	}
+
+	for i := range results {
+		r := &results[i]
+		if r.Verifier == "gocode" || !IsTimeSensitive(r.Claim) {
+			continue
+		}
+
+		r.TimeSensitive = true
+		r.AsOf = asOf
+		r.EvidenceDate = evidenceDate(r.KeyQuote, r.ReviewURL)
+
+		r.PossiblyOutdated = r.Found && asOf != "" && r.EvidenceDate != "" && datesDiffer(r.EvidenceDate, asOf)
+	}
+}
@@ -0,0 +1,81 @@
+// Go/topics.go
+
+package main
+
+import "regexp"
+
+// Темы проверок для статистики: определяются по словам вопроса и утверждений
+const (
+	TopicHistory    = "history"
+	TopicGeography  = "geography"
+	TopicScience    = "science"
+	TopicMedicine   = "medicine"
+	TopicTechnology = "technology"
+	TopicEconomics  = "economics"
+	TopicSports     = "sports"
+	TopicCulture    = "culture"
+	TopicOther      = "other"
+)
+
+// topicPatterns - основы слов по темам; порядок решает при равном числе совпадений.
+// Основы ищутся с начала слова, а у коротких основ вроде "поэт" перечислены
+// окончания, чтобы "поэтому" не считалось словом о поэзии.
+var topicPatterns = []struct {
+	topic   string
+	pattern *regexp.Regexp
+}{
+	{TopicHistory, topicStems(`битв|войн|сражен|импер|царств|царь|князь|династ|революц|восстан|летопис|battle|wars?\b|empire|dynasty|revolution|king|queen|medieval`)},
+	{TopicGeography, topicStems(`столиц|стран[аеуы]|город|река|реки|озер|гора|горы|океан|море|остров|материк|населени|capital|countr|city|river|lake|mountain|ocean|island|continent|population`)},
+	{TopicScience, topicStems(`физик|хими|биолог|атом|молекул|клетк|планет|звезд|галактик|эволюц|гравитац|скорость света|physics|chemistr|biolog|atom|molecul|planet|stars?\b|galax|evolution|gravity`)},
+	{TopicMedicine, topicStems(`болезн|вирус|вакцин|лекарств|здоров|медицин|витамин|антибиотик|диагноз|disease|virus|vaccin|drugs?\b|health|medic|vitamin|antibiotic`)},
+	{TopicTechnology, topicStems(`компьютер|программ|интернет|процессор|алгоритм|нейросет|смартфон|golang|python|go:|software|computer|internet|processor|algorithm|neural|smartphone`)},
+	{TopicEconomics, topicStems(`экономик|ввп|инфляц|валют|рубл|доллар|бирж|банк|налог|бюджет|gdp\b|inflation|currenc|dollar|stock|bank|tax|budget`)},
+	{TopicSports, topicStems(`чемпионат|олимпи|матч|футбол|хоккей|теннис|спорт|рекорд мира|olympi|championship|football|soccer|hockey|tennis|sport`)},
+	{TopicCulture, topicStems(`роман|писател|поэт(?:[аеуы]|ом|ов|ами?|ах|есс\p{L}*|ическ\p{L}*)?(?:[^\p{L}]|$)|стих(?:и|ов|ам|ами|ах|а|отвор\p{L}*)?(?:[^\p{L}]|$)|картин|художник|фильм|музык|композитор|театр|novel|writer|poet|painting|artist|films?\b|movie|music|composer|theat`)},
+}
+
+// topicStems собирает шаблон темы: основа должна начинать слово. \b в RE2
+// понимает только ASCII, поэтому граница задаётся явно, иначе "налог"
+// нашёлся бы в "аналогично"
+func topicStems(stems string) *regexp.Regexp {
+	return regexp.MustCompile(`(?i)(?:^|[^\p{L}\p{N}])(?:` + stems + `)`)
+}
+
+// ClassifyTopic выбирает тему с наибольшим числом совпадений в вопросе
+// и утверждениях; вопрос весит вдвое больше, так как задаёт тему разговора
+func ClassifyTopic(query string, claims []string) string {
+	best, bestScore := TopicOther, 0
+	for _, tp := range topicPatterns {
+		score := 2 * len(tp.pattern.FindAllStringIndex(query, -1))
+		for _, claim := range claims {
+			score += len(tp.pattern.FindAllStringIndex(claim, -1))
+		}
+		if score > bestScore {
+			best, bestScore = tp.topic, score
+		}
+	}
+	return best
+}
+
+// topicLabel - название темы для отчётов
+func topicLabel(topic string) string {
+	switch topic {
+	case TopicHistory:
+		return "история"
+	case TopicGeography:
+		return "география"
+	case TopicScience:
+		return "наука"
+	case TopicMedicine:
+		return "медицина"
+	case TopicTechnology:
+		return "технологии"
+	case TopicEconomics:
+		return "экономика"
+	case TopicSports:
+		return "спорт"
+	case TopicCulture:
+		return "культура"
+	}
+	return "другое"
+}
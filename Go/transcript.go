@@ -0,0 +1,251 @@
+// Go/transcript.go
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/muesli/termenv"
+)
+
+// ChatMessage - реплика диалога после приведения к общему виду
+type ChatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// TranscriptTurn - проверка одного ответа ассистента.
+// Message - номер реплики в диалоге (с 1), Query - предшествующий вопрос пользователя.
+type TranscriptTurn struct {
+	Message  int             `json:"message"`
+	Query    string          `json:"query,omitempty"`
+	Response string          `json:"response"`
+	Analysis *AnalysisResult `json:"analysis,omitempty"`
+	Error    string          `json:"error,omitempty"`
+}
+
+// TranscriptReport - итог по всему диалогу: сводка по всем утверждениям,
+// наибольший риск среди ответов и худший итог политики
+type TranscriptReport struct {
+	File    string           `json:"file"`
+	Turns   []TranscriptTurn `json:"turns"`
+	Summary ResultSummary    `json:"summary"`
+	MaxRisk float64          `json:"max_risk"`
+	Policy  string           `json:"policy_status,omitempty"`
+}
+
+// rawMessage - реплика в любом из поддерживаемых форматов: content - строка
+// (OpenAI) или массив блоков {type, text} (Anthropic, OpenAI multimodal)
+type rawMessage struct {
+	Role    string          `json:"role"`
+	Content json.RawMessage `json:"content"`
+}
+
+// LoadTranscript читает диалог из JSON-массива сообщений, объекта с полем
+// messages или JSONL (одно сообщение на строку)
+func LoadTranscript(path string) ([]ChatMessage, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось прочитать файл: %w", err)
+	}
+	data = bytes.TrimSpace(data)
+
+	var raw []rawMessage
+	switch {
+	case bytes.HasPrefix(data, []byte("[")):
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return nil, fmt.Errorf("ошибка парсинга диалога: %w", err)
+		}
+	default:
+		var wrapped struct {
+			Messages []rawMessage `json:"messages"`
+		}
+		if err := json.Unmarshal(data, &wrapped); err == nil && len(wrapped.Messages) > 0 {
+			raw = wrapped.Messages
+			break
+		}
+		raw, err = parseJSONLMessages(data)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	messages := make([]ChatMessage, 0, len(raw))
+	for _, m := range raw {
+		text, err := messageText(m.Content)
+		if err != nil {
+			return nil, fmt.Errorf("реплика %d: %w", len(messages)+1, err)
+		}
+		messages = append(messages, ChatMessage{Role: normalizeRole(m.Role), Content: text})
+	}
+	if len(messages) == 0 {
+		return nil, fmt.Errorf("в файле нет сообщений")
+	}
+	return messages, nil
+}
+
+func parseJSONLMessages(data []byte) ([]rawMessage, error) {
+	var raw []rawMessage
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), maxInputSize)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+		var m rawMessage
+		if err := json.Unmarshal(text, &m); err != nil {
+			return nil, fmt.Errorf("строка %d: ошибка парсинга JSONL: %w", line, err)
+		}
+		raw = append(raw, m)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка чтения JSONL: %w", err)
+	}
+	return raw, nil
+}
+
+// messageText склеивает текстовые блоки; картинки, вызовы инструментов и т.п. пропускаются
+func messageText(content json.RawMessage) (string, error) {
+	if len(content) == 0 || string(content) == "null" {
+		return "", nil
+	}
+
+	var text string
+	if err := json.Unmarshal(content, &text); err == nil {
+		return text, nil
+	}
+
+	var blocks []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(content, &blocks); err != nil {
+		return "", fmt.Errorf("неизвестный формат content: %w", err)
+	}
+	var parts []string
+	for _, b := range blocks {
+		switch b.Type {
+		case "text", "input_text", "output_text":
+			parts = append(parts, b.Text)
+		}
+	}
+	return strings.Join(parts, "\n"), nil
+}
+
+// normalizeRole сводит роли разных API к user/assistant
+func normalizeRole(role string) string {
+	switch strings.ToLower(role) {
+	case "assistant", "model", "ai", "bot":
+		return "assistant"
+	case "user", "human":
+		return "user"
+	}
+	return strings.ToLower(role)
+}
+
+// transcriptTurns - ответы ассистента с ближайшим предшествующим вопросом пользователя
+func transcriptTurns(messages []ChatMessage) []TranscriptTurn {
+	var turns []TranscriptTurn
+	query := ""
+	for i, m := range messages {
+		switch m.Role {
+		case "user":
+			query = m.Content
+		case "assistant":
+			if strings.TrimSpace(m.Content) == "" {
+				continue
+			}
+			turns = append(turns, TranscriptTurn{Message: i + 1, Query: query, Response: m.Content})
+		}
+	}
+	return turns
+}
+
+// runTranscript проверяет каждый ответ ассистента и собирает отчёт по диалогу
+func runTranscript(path string, opts checkOptions, p termenv.Profile) *TranscriptReport {
+	colorErr := p.Color("#FF6B6B")
+	colorHeader := p.Color("#00BFFF")
+
+	messages, err := LoadTranscript(path)
+	if err != nil {
+		fmt.Println(termenv.String(fmt.Sprintf("  ❌ %v", err)).Foreground(colorErr))
+		return nil
+	}
+	turns := transcriptTurns(messages)
+	if len(turns) == 0 {
+		fmt.Println(termenv.String("  ❌ В диалоге нет ответов ассистента").Foreground(colorErr))
+		return nil
+	}
+
+	report := &TranscriptReport{File: path}
+	var all []FactCheckResult
+	for i := range turns {
+		turn := &turns[i]
+		fmt.Println(termenv.String(fmt.Sprintf("\n  ━━━ Ответ %d из %d (реплика %d) ━━━", i+1, len(turns), turn.Message)).Foreground(colorHeader).Bold())
+
+		analysis, err := runFull(turn.Query, turn.Response, opts, p)
+		if errors.Is(err, errNoClaims) {
+			turn.Analysis = noClaimsAnalysis(turn.Query, turn.Response, opts)
+			continue
+		}
+		if err != nil {
+			turn.Error = err.Error()
+			continue
+		}
+		turn.Analysis = analysis
+
+		all = append(all, turn.Analysis.FactCheckResults...)
+		report.MaxRisk = max(report.MaxRisk, turn.Analysis.Risk.Score)
+		if pr := turn.Analysis.Policy; pr != nil && policyStatusRank(pr.Status) > policyStatusRank(report.Policy) {
+			report.Policy = pr.Status
+		}
+	}
+
+	report.Turns = turns
+	report.Summary = BuildSummary(all)
+	return report
+}
+
+// policyStatusRank упорядочивает итоги политики от лучшего к худшему
+func policyStatusRank(status string) int {
+	switch status {
+	case "fail":
+		return 3
+	case "warn":
+		return 2
+	case "pass":
+		return 1
+	}
+	return 0
+}
+
+// ExitCode - код выхода по худшему ответу; ошибка проверки хоть одного ответа - exitError
+func (r *TranscriptReport) ExitCode() int {
+	for _, t := range r.Turns {
+		if t.Error != "" {
+			return exitError
+		}
+	}
+	if r.Policy == "fail" {
+		return exitPolicyFail
+	}
+	return exitOK
+}
+
+// truncateText обрезает текст до n символов для таблиц и списков
+func truncateText(text string, n int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if runes := []rune(text); len(runes) > n {
+		return string(runes[:n-1]) + "…"
+	}
+	return text
+}
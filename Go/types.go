@@ -1,12 +1,34 @@
 package main
 
+import "time"
+
 // ClaimsData - структура JSON файла с утверждениями
 type ClaimsData struct {
-	Timestamp string   `json:"timestamp"`
-	Query     string   `json:"query"`
-	Response  string   `json:"response"`
-	Claims    []string `json:"claims"`
-	Count     int      `json:"count"`
+	Timestamp string      `json:"timestamp"`
+	Query     string      `json:"query"`
+	Response  string      `json:"response"`
+	Claims    []string    `json:"claims"`
+	Spans     []ClaimSpan `json:"spans,omitempty"`
+	Count     int         `json:"count"`
+}
+
+// ClaimSpan - место утверждения в ответе. Start/End - позиции в символах
+// (не байтах), End не включается; nil, если извлекатель их не нашёл.
+type ClaimSpan struct {
+	Claim    string `json:"claim"`
+	Start    *int   `json:"start"`
+	End      *int   `json:"end"`
+	Sentence string `json:"sentence,omitempty"`
+	Method   string `json:"method,omitempty"`
+}
+
+// Reference - источник, который вернул Jina Grounding API
+type Reference struct {
+	URL          string  `json:"url"`
+	KeyQuote     string  `json:"key_quote,omitempty"`
+	IsSupportive bool    `json:"is_supportive"`
+	Domain       string  `json:"domain,omitempty"`
+	Credibility  float64 `json:"credibility,omitempty"`
 }
 
 // FactCheckResult - результат проверки через Jina Grounding API
@@ -19,21 +41,80 @@ type FactCheckResult struct {
 	ReviewURL  string  `json:"review_url,omitempty"`
 	Confidence float64 `json:"confidence"`
 	KeyQuote   string  `json:"key_quote,omitempty"`
+	Verifier   string  `json:"verifier,omitempty"`
+	Verdict    Verdict `json:"verdict"`
+	ClaimType  string  `json:"claim_type,omitempty"`
+
+	// Span - где утверждение находится в исходном ответе
+	Span  *ClaimSpan `json:"span,omitempty"`
+	Error string     `json:"error,omitempty"`
+
+	// DurationMs - сколько заняла проверка утверждения
+	DurationMs int64 `json:"duration_ms,omitempty"`
+
+	// References - все источники; ReviewURL/KeyQuote - выбранный из них
+	References      []Reference `json:"references,omitempty"`
+	SupportingCount int         `json:"supporting_count"`
+	RefutingCount   int         `json:"refuting_count"`
+	SupportRatio    float64     `json:"support_ratio"`
+
+	// Выбранный источник и доверие к нему; RawFactuality - оценка до перевзвешивания
+	SourceDomain      string  `json:"source_domain,omitempty"`
+	SourceCredibility float64 `json:"source_credibility,omitempty"`
+	RawFactuality     float64 `json:"raw_factuality,omitempty"`
+
+	// Calibration - метод, которым откалибрована Confidence по оценкам
+	// рецензентов; RawConfidence - уверенность проверщика до калибровки
+	Calibration   string  `json:"calibration,omitempty"`
+	RawConfidence float64 `json:"raw_confidence,omitempty"`
+
+	// MatchedMyth - запись базы заблуждений, с которой совпало утверждение
+	MatchedMyth string `json:"matched_myth,omitempty"`
+
+	// Mismatches - где утверждение расходится с цитатой источника
+	Mismatches []ClaimMismatch `json:"mismatches,omitempty"`
+
+	// Временной контекст: AsOf - дата знаний модели, EvidenceDate - дата источника
+	TimeSensitive    bool   `json:"time_sensitive,omitempty"`
+	AsOf             string `json:"as_of,omitempty"`
+	EvidenceDate     string `json:"evidence_date,omitempty"`
+	PossiblyOutdated bool   `json:"possibly_outdated,omitempty"`
 }
 
 // AnalysisResult - полный результат анализа
 type AnalysisResult struct {
-	Query            string            `json:"query"`
-	Response         string            `json:"response"`
-	Claims           []string          `json:"claims"`
-	FactCheckResults []FactCheckResult `json:"factcheck_results"`
-	Summary          ResultSummary     `json:"summary"`
+	Query            string             `json:"query"`
+	Response         string             `json:"response"`
+	Claims           []string           `json:"claims"`
+	FactCheckResults []FactCheckResult  `json:"factcheck_results"`
+	Summary          ResultSummary      `json:"summary"`
+	Risk             RiskScore          `json:"risk"`
+	Relevance        *RelevanceReport   `json:"relevance,omitempty"`
+	Policy           *PolicyReport      `json:"policy,omitempty"`
+	Correction       *CorrectedResponse `json:"correction,omitempty"`
+	Warnings         []string           `json:"warnings,omitempty"`
+
+	// CheckedAt и DurationMs - когда и сколько шла проверка;
+	// HistoryID - номер записи в истории, если она сохранена
+	CheckedAt  time.Time `json:"checked_at"`
+	DurationMs int64     `json:"duration_ms,omitempty"`
+	HistoryID  int64     `json:"history_id,omitempty"`
+
+	// Model - метка модели, давшей ответ; Topic - тема для статистики
+	Model string `json:"model,omitempty"`
+	Topic string `json:"topic,omitempty"`
 }
 
-// ResultSummary - сводка результатов
+// ResultSummary - сводка результатов по вердиктам.
+// HallucinationRate считается только по проверенным утверждениям:
+// непроверяемые и ошибки проверки в знаменатель не входят.
 type ResultSummary struct {
-	TotalClaims             int `json:"total_claims"`
-	ClaimsFound             int `json:"claims_found"`
-	ClaimsNotFound          int `json:"claims_not_found"`
-	PotentialHallucinations int `json:"potential_hallucinations"`
+	TotalClaims       int     `json:"total_claims"`
+	Supported         int     `json:"supported"`
+	Refuted           int     `json:"refuted"`
+	Disputed          int     `json:"disputed"`
+	Unverifiable      int     `json:"unverifiable"`
+	Errors            int     `json:"errors"`
+	PossiblyOutdated  int     `json:"possibly_outdated"`
+	HallucinationRate float64 `json:"hallucination_rate"`
 }
@@ -0,0 +1,115 @@
+// Go/verdict.go
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+const verdictsFile = "data/verdicts.json"
+
+// Verdict - итоговая оценка утверждения
+type Verdict string
+
+const (
+	VerdictSupported    Verdict = "supported"    // источники подтверждают
+	VerdictRefuted      Verdict = "refuted"      // источники опровергают - галлюцинация
+	VerdictDisputed     Verdict = "disputed"     // источники расходятся
+	VerdictUnverifiable Verdict = "unverifiable" // проверка прошла, но доказательств нет
+	VerdictError        Verdict = "error"        // проверка не состоялась (сеть, API)
+)
+
+// VerdictThresholds - пороги factuality для вердиктов.
+// factuality >= Supported - подтверждено, <= Refuted - опровергнуто, между ними - спорно.
+// Если источников меньше MinReferences, утверждение считается непроверяемым.
+type VerdictThresholds struct {
+	Supported     float64 `json:"supported"`
+	Refuted       float64 `json:"refuted"`
+	MinReferences int     `json:"min_references"`
+}
+
+// DefaultVerdictThresholds - пороги, если data/verdicts.json отсутствует
+func DefaultVerdictThresholds() VerdictThresholds {
+	return VerdictThresholds{Supported: 0.7, Refuted: 0.3, MinReferences: 1}
+}
+
+// LoadVerdictThresholds читает пороги; незаданные поля берутся по умолчанию
+func LoadVerdictThresholds(path string) (VerdictThresholds, error) {
+	thresholds := DefaultVerdictThresholds()
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return thresholds, nil
+	}
+	if err != nil {
+		return thresholds, fmt.Errorf("не удалось прочитать %s: %w", path, err)
+	}
+
+	if err := json.Unmarshal(data, &thresholds); err != nil {
+		return DefaultVerdictThresholds(), fmt.Errorf("ошибка парсинга %s: %w", path, err)
+	}
+	if thresholds.Refuted > thresholds.Supported {
+		return DefaultVerdictThresholds(), fmt.Errorf("%s: порог refuted (%.2f) выше supported (%.2f)", path, thresholds.Refuted, thresholds.Supported)
+	}
+	return thresholds, nil
+}
+
+// Classify выносит вердикт по результату проверки.
+// Локальные проверщики (база мифов, Go-код) ставят вердикт сами - он не меняется.
+func (t VerdictThresholds) Classify(r FactCheckResult) Verdict {
+	switch {
+	case r.Verdict != "":
+		return r.Verdict
+	case !r.Found:
+		return VerdictError
+	case len(r.References) < t.MinReferences:
+		return VerdictUnverifiable
+	case r.Factuality >= t.Supported:
+		return VerdictSupported
+	case r.Factuality <= t.Refuted:
+		return VerdictRefuted
+	default:
+		return VerdictDisputed
+	}
+}
+
+// ClassifyAll проставляет вердикты всем результатам
+func (t VerdictThresholds) ClassifyAll(results []FactCheckResult) {
+	for i := range results {
+		results[i].Verdict = t.Classify(results[i])
+	}
+}
+
+// verdictLabel - подпись вердикта для отчётов
+func verdictLabel(v Verdict) string {
+	switch v {
+	case VerdictSupported:
+		return "ФАКТ ПОДТВЕРЖДЁН"
+	case VerdictRefuted:
+		return "ГАЛЛЮЦИНАЦИЯ"
+	case VerdictDisputed:
+		return "СПОРНО"
+	case VerdictUnverifiable:
+		return "НЕПРОВЕРЯЕМО (нет источников)"
+	case VerdictError:
+		return "ОШИБКА ПРОВЕРКИ"
+	}
+	return "НЕ ПРОВЕРЕНО"
+}
+
+func verdictIcon(v Verdict) string {
+	switch v {
+	case VerdictSupported:
+		return "✅"
+	case VerdictRefuted:
+		return "❌"
+	case VerdictDisputed:
+		return "⚖️ "
+	case VerdictUnverifiable:
+		return "❔"
+	}
+	return "⚠️ "
+}
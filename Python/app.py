@@ -2,7 +2,7 @@
 
 from fastapi import FastAPI, HTTPException
 from pydantic import BaseModel
-from typing import List
+from typing import List, Optional
 import logging
 import json
 from datetime import datetime
@@ -46,10 +46,19 @@ class ExtractClaimsRequest(BaseModel):
         }
 
 
+class ClaimSpan(BaseModel):
+    """Позиция утверждения в исходном тексте (в символах, end не включается)"""
+    claim: str
+    start: Optional[int] = None
+    end: Optional[int] = None
+    sentence: str = ""
+
+
 class ExtractClaimsResponse(BaseModel):
     """Ответ с извлеченными утверждениями"""
     claims: List[str]
     count: int
+    spans: List[ClaimSpan] = []
     
     class Config:
         json_schema_extra = {
@@ -98,11 +107,12 @@ def extract_claims_endpoint(request: ExtractClaimsRequest):
     try:
         logger.info(f"Извлечение утверждений из текста ({len(request.text)} символов)")
         
-        claims = extractor.extract(request.text)
+        spans = extractor.extract_with_spans(request.text)
+        claims = [span["claim"] for span in spans]
         
         logger.info(f"✓ Извлечено {len(claims)} утверждений")
         
-        return ExtractClaimsResponse(claims=claims, count=len(claims))
+        return ExtractClaimsResponse(claims=claims, count=len(claims), spans=spans)
         
     except Exception as e:
         logger.error(f"Ошибка при извлечении: {e}", exc_info=True)
@@ -137,7 +147,8 @@ def extract_and_save_endpoint(request: ExtractClaimsRequest):
     try:
         # Извлечение утверждений
         logger.info(f"Извлечение утверждений из текста ({len(request.text)} символов)")
-        claims = extractor.extract(request.text)
+        spans = extractor.extract_with_spans(request.text)
+        claims = [span["claim"] for span in spans]
         logger.info(f"✓ Извлечено {len(claims)} утверждений")
         
         # Создание структуры для сохранения
@@ -146,6 +157,7 @@ def extract_and_save_endpoint(request: ExtractClaimsRequest):
             "query": request.query,
             "response": request.text,
             "claims": claims,
+            "spans": spans,
             "count": len(claims)
         }
         
@@ -167,7 +179,8 @@ def extract_and_save_endpoint(request: ExtractClaimsRequest):
             "success": True,
             "filename": str(filepath),
             "claims_count": len(claims),
-            "claims": claims
+            "claims": claims,
+            "spans": spans
         }
         
     except Exception as e:
@@ -1,6 +1,6 @@
 import os
 import logging
-from typing import List
+from typing import Any, Dict, List, Optional, Tuple
 
 # Импортируем библиотеку и её типы данных
 import langextract as lx
@@ -28,6 +28,16 @@ class ClaimExtractor:
     
     def extract(self, text: str) -> List[str]:
         """Извлекает утверждения из текста"""
+        return [item["claim"] for item in self.extract_with_spans(text)]
+
+    def extract_with_spans(self, text: str) -> List[Dict[str, Any]]:
+        """
+        Извлекает утверждения вместе с позициями в исходном тексте.
+
+        start/end - смещения в символах (а не байтах), end не включается;
+        sentence - предложение, из которого взято утверждение.
+        Если langextract не смог выровнять извлечение, start/end равны None.
+        """
         
         prompt = """
         Extract all verifiable claims and facts from the text.
@@ -65,6 +75,7 @@ class ClaimExtractor:
             )
             
             claims = []
+            seen = set()
 
             # Проверяем: если это не список, а один объект (AnnotatedDocument)
             # делаем его списком, чтобы наш код ниже сработал в обоих случаях
@@ -82,15 +93,47 @@ class ClaimExtractor:
                         else:
                             val = item.extraction_text
                         
-                        if val:
-                            claims.append(val)
+                        if not val or val in seen:
+                            continue
+                        seen.add(val)
+
+                        start, end = _char_interval(item)
+                        claims.append({
+                            "claim": val,
+                            "start": start,
+                            "end": end,
+                            "sentence": _sentence_at(text, start, end),
+                        })
             
-            return list(set(claims))
+            return claims
 
         except Exception as e:
             logger.error(f"Ошибка при работе LangExtract: {e}")
             return []
 
+def _char_interval(item) -> Tuple[Optional[int], Optional[int]]:
+    """Позиции извлечения в тексте, если langextract их нашёл"""
+    interval = getattr(item, 'char_interval', None)
+    if interval is None:
+        return None, None
+    start = getattr(interval, 'start_pos', None)
+    end = getattr(interval, 'end_pos', None)
+    if start is None or end is None or end < start:
+        return None, None
+    return start, end
+
+
+def _sentence_at(text: str, start: Optional[int], end: Optional[int]) -> str:
+    """Предложение, содержащее интервал [start, end)"""
+    if start is None or end is None:
+        return ""
+    left = max(text.rfind(sep, 0, start) for sep in ".!?\n")
+    left = 0 if left < 0 else left + 1
+    rights = [pos for pos in (text.find(sep, end) for sep in ".!?\n") if pos >= 0]
+    right = min(rights) + 1 if rights else len(text)
+    return text[left:right].strip()
+
+
 # Функция для быстрого вызова
 def extract_claims(text: str, api_key: str = None) -> List[str]:
     return ClaimExtractor(api_key).extract(text)